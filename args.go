@@ -0,0 +1,139 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Separators of request items of command line, two chars separators go first
+// to take precedence at the same position.
+var argSeparators = []string{":=", "==", "=", ":", "@"}
+
+// Split request item by the first separator: name, separator and value.
+func splitRequestItem(item string) (string, string, string, bool) {
+	for i := range item {
+		for _, sep := range argSeparators {
+			if strings.HasPrefix(item[i:], sep) {
+				return item[:i], sep, item[i+len(sep):], true
+			}
+		}
+	}
+	return "", "", "", false
+}
+
+// Parse command line arguments in HTTPie style: [METHOD] URL [ITEM...], items are
+// Header:value, param==value, field=value, field@/path/to/file and field:=raw JSON.
+// Fields are sent as JSON object (as HTTPie does) or as form if asForm is set, fields
+// with files are sent as multipart form, the value of field=@value is not a file.
+// The scheme of request is empty if URL has no one.
+func ParseRequestArgs(args []string, asForm bool) (*Curl, error) {
+	c := &Curl{
+		Request: Request{
+			Headers:    make(map[string][]string),
+			FormValues: make(map[string][]string),
+		},
+	}
+	if len(args) > 1 && slices.Contains(allowedMethods, args[0]) {
+		c.Method, args = args[0], args[1:]
+	}
+	if len(args) == 0 {
+		return nil, errors.New("URL is not found")
+	}
+
+	rawURL := args[0]
+	if strings.HasPrefix(rawURL, ":") { // localhost shorthand: :3000/path
+		rawURL = "localhost" + rawURL
+	}
+	var scheme string
+	if s, rest, ok := strings.Cut(rawURL, "://"); ok {
+		scheme, rawURL = s, rest
+	}
+	u, err := url.Parse("http://" + rawURL)
+	if err != nil {
+		return nil, err
+	}
+	c.Scheme = scheme
+	c.Host = u.Host
+	c.UrlPath = u.Path
+	query := u.Query()
+
+	var (
+		files  bool
+		fields []string                    // names of fields in order of arguments
+		raw    = map[string]bool{}         // fields with raw JSON values
+		values = map[string]string{}       // values of JSON fields
+		form   = make(map[string][]string) // values of form fields
+	)
+	for _, item := range args[1:] {
+		name, sep, val, ok := splitRequestItem(item)
+		if !ok || name == "" {
+			return nil, errors.New("invalid request item: " + item)
+		}
+		switch sep {
+		case ":":
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			c.Headers[name] = append(c.Headers[name], strings.TrimSpace(val))
+		case "==":
+			query.Add(name, val)
+		case ":=":
+			if !json.Valid([]byte(val)) {
+				return nil, errors.New("invalid JSON of field " + name + ": " + val)
+			}
+			raw[name] = true
+			fallthrough
+		case "=", "@":
+			if _, ok := values[name]; !ok {
+				fields = append(fields, name)
+			}
+			values[name] = val
+			switch {
+			case sep == "@":
+				files = true
+				val = "@" + val
+			case strings.HasPrefix(val, "@"):
+				val = `\` + val // escaped: it's not a file of multipart form
+			}
+			form[name] = append(form[name], val)
+		}
+	}
+	c.RawQuery = query.Encode()
+
+	switch {
+	case len(raw) > 0 && (files || asForm):
+		return nil, errors.New("raw JSON fields cannot be sent as form")
+	case files:
+		c.FormValues = form
+		c.Payload = multipartPayload
+	case asForm && len(form) > 0:
+		c.FormValues = form
+		c.Payload = formPayload
+	case len(fields) > 0:
+		obj := make(map[string]json.RawMessage)
+		for _, name := range fields {
+			v := values[name]
+			if !raw[name] {
+				b, _ := json.Marshal(v)
+				v = string(b)
+			}
+			obj[name] = json.RawMessage(v)
+		}
+		b, err := json.Marshal(obj)
+		if err != nil {
+			return nil, err
+		}
+		c.JSON = string(b)
+		c.Payload = jsonPayload
+	}
+
+	if c.Method == "" {
+		c.Method = "GET"
+		if c.Payload != nothing {
+			c.Method = "POST"
+		}
+	}
+	return c, nil
+}
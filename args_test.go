@@ -0,0 +1,75 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseRequestArgs(t *testing.T) {
+	t.Run("form", func(t *testing.T) {
+		c, err := ParseRequestArgs([]string{
+			"PUT", "https://example.com/api?a=1", "X-Token:abc", "q==go lang", "name=rhttp", "url=http://x"}, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Method != "PUT" || c.Scheme != "https" || c.Host != "example.com" || c.UrlPath != "/api" {
+			t.Errorf("expected PUT https://example.com/api, got: %s %s://%s%s", c.Method, c.Scheme, c.Host, c.UrlPath)
+		}
+		if c.RawQuery != "a=1&q=go+lang" {
+			t.Errorf("expected query a=1&q=go+lang, got: %s", c.RawQuery)
+		}
+		if v := c.Headers["X-Token"]; !slices.Equal(v, []string{"abc"}) {
+			t.Errorf("expected header X-Token: abc, got: %v", v)
+		}
+		if c.Payload != formPayload || c.FormValues["name"][0] != "rhttp" || c.FormValues["url"][0] != "http://x" {
+			t.Errorf("expected form payload, got: %d %v", c.Payload, c.FormValues)
+		}
+	})
+
+	t.Run("json", func(t *testing.T) {
+		c, err := ParseRequestArgs([]string{":3000/users", "name=rhttp", "age:=3", "tags:=[\"a\"]"}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Method != "POST" || c.Scheme != "" || c.Host != "localhost:3000" {
+			t.Errorf("expected POST localhost:3000 without scheme, got: %s %s %s", c.Method, c.Scheme, c.Host)
+		}
+		if c.Payload != jsonPayload || c.JSON != `{"age":3,"name":"rhttp","tags":["a"]}` {
+			t.Errorf("expected JSON payload, got: %d %s", c.Payload, c.JSON)
+		}
+
+		c, _ = ParseRequestArgs([]string{"example.com", "name=rhttp", "avatar=@me"}, false)
+		if c.Payload != jsonPayload || c.JSON != `{"avatar":"@me","name":"rhttp"}` {
+			t.Errorf("expected JSON payload of string fields, got: %d %s", c.Payload, c.JSON)
+		}
+	})
+
+	t.Run("files", func(t *testing.T) {
+		c, err := ParseRequestArgs([]string{"example.com", "avatar@/tmp/a.png", "name=@me"}, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.Method != "POST" || c.Payload != multipartPayload || c.FormValues["avatar"][0] != "@/tmp/a.png" {
+			t.Errorf("expected multipart payload, got: %s %d %v", c.Method, c.Payload, c.FormValues)
+		}
+		if v := c.FormValues["name"][0]; v != `\@me` || hasFileParts(map[string][]string{"name": {v}}) {
+			t.Errorf("expected escaped literal value of field, got: %s", v)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, args := range [][]string{
+			{},
+			{"example.com", "invalid"},
+			{"example.com", "n:=invalid"},
+			{"example.com", "f@a.txt", "n:=1"},
+		} {
+			if _, err := ParseRequestArgs(args, false); err == nil {
+				t.Errorf("expected error of %v", args)
+			}
+		}
+		if _, err := ParseRequestArgs([]string{"example.com", "n:=1"}, true); err == nil {
+			t.Error("expected error of raw JSON field of form")
+		}
+	})
+}
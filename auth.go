@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Types of authentication.
+const (
+	authNone        = "None"
+	authBasic       = "Basic"
+	authBearer      = "Bearer"
+	authAPIKey      = "APIKey"
+	authAPIKeyQuery = "APIKeyQuery"
+	authDigest      = "Digest"
+	authOAuth2      = "OAuth2"
+	authSigV4       = "AWSSigV4"
+	authHMAC        = "HMAC"
+)
+
+var authTypes = []string{
+	authNone, authBasic, authBearer, authAPIKey, authAPIKeyQuery, authDigest, authOAuth2, authSigV4, authHMAC}
+
+// Auth settings of request, they are applied to request at send time.
+type Auth struct {
+	Type     string `json:"type"`
+	Name     string `json:"name,omitempty"`     // name of header or query param of API key, name of signer
+	User     string `json:"user,omitempty"`     // user, access key of AWS or key id of signer
+	Password string `json:"password,omitempty"` // password, secret key of AWS or key of signer
+	Token    string `json:"token,omitempty"`    // bearer token, API key, refresh token of OAuth2 or session token of AWS
+
+	// OAuth2 settings
+	TokenURL     string `json:"token_url,omitempty"`
+	ClientID     string `json:"client_id,omitempty"`
+	ClientSecret string `json:"client_secret,omitempty"`
+	Scopes       string `json:"scopes,omitempty"` // space separated
+	Audience     string `json:"audience,omitempty"`
+
+	// AWS Signature V4 settings
+	Region  string `json:"region,omitempty"`
+	Service string `json:"service,omitempty"`
+}
+
+// Auth settings of current request.
+var reqAuth Auth
+
+// Check if auth is turned on.
+func (a *Auth) IsSet() bool {
+	return a.Type != "" && a.Type != authNone
+}
+
+// Short description of auth settings, secrets are hidden.
+func (a *Auth) String() string {
+	switch a.Type {
+	case authBasic, authDigest:
+		return a.Type + " " + a.User + ":***"
+	case authBearer:
+		return a.Type + " ***"
+	case authAPIKey:
+		return "API key in header " + a.Name
+	case authAPIKeyQuery:
+		return "API key in query param " + a.Name
+	case authOAuth2:
+		return a.Type + " " + a.ClientID + "@" + a.TokenURL
+	case authSigV4:
+		return a.Type + " " + a.User + " " + a.Region + "/" + a.Service
+	case authHMAC:
+		return a.Type + " signer " + a.Name + " " + a.User + ":***"
+	}
+	return authNone
+}
+
+// Expand placeholders of auth settings by variables of active environment.
+func expandAuth(a Auth) Auth {
+	for _, s := range []*string{&a.Name, &a.User, &a.Password, &a.Token, &a.TokenURL, &a.ClientID,
+		&a.ClientSecret, &a.Scopes, &a.Audience, &a.Region, &a.Service} {
+		*s = expandVars(*s)
+	}
+	return a
+}
+
+// Apply auth settings to request, placeholders must be expanded by [expandAuth].
+// Digest auth needs a challenge of server, so it's applied by [doDigestAuth],
+// OAuth2 needs an access token, it's taken by [oauth2Token], AWS Signature V4
+// covers the body, so it's applied by [signSigV4] after the body is set, HMAC signature
+// is applied by [signHMAC] as the last step before sending request.
+func applyAuth(r *http.Request, a Auth) {
+	switch a.Type {
+	case authBasic:
+		r.SetBasicAuth(a.User, a.Password)
+	case authBearer:
+		r.Header.Set("Authorization", "Bearer "+a.Token)
+	case authAPIKey:
+		r.Header.Set(a.Name, a.Token)
+	case authAPIKeyQuery:
+		q := r.URL.Query()
+		q.Set(a.Name, a.Token)
+		r.URL.RawQuery = q.Encode()
+	}
+}
+
+// Parse the Digest challenge of WWW-Authenticate header.
+func parseDigestChallenge(h string) (map[string]string, error) {
+	scheme, params, _ := strings.Cut(strings.TrimSpace(h), " ")
+	if !strings.EqualFold(scheme, "Digest") {
+		return nil, errors.New("server does not support Digest auth: " + h)
+	}
+	c := make(map[string]string)
+	for params != "" {
+		var name, val string
+		name, params, _ = strings.Cut(strings.TrimLeft(params, " ,"), "=")
+		if strings.HasPrefix(params, `"`) {
+			val, params, _ = strings.Cut(params[1:], `"`)
+		} else {
+			val, params, _ = strings.Cut(params, ",")
+		}
+		c[strings.ToLower(strings.TrimSpace(name))] = strings.TrimSpace(val)
+	}
+	if c["nonce"] == "" {
+		return nil, errors.New("nonce is missed in Digest challenge")
+	}
+	return c, nil
+}
+
+// Compute Authorization header of Digest auth (RFC 7616).
+func digestAuthorization(c map[string]string, method, uri, user, password, cnonce string, body []byte) string {
+	var newHash func() hash.Hash = md5.New
+	algorithm := strings.ToUpper(c["algorithm"])
+	if strings.HasPrefix(algorithm, "SHA-256") {
+		newHash = sha256.New
+	}
+	h := func(s ...string) string {
+		d := newHash()
+		d.Write([]byte(strings.Join(s, ":")))
+		return hex.EncodeToString(d.Sum(nil))
+	}
+
+	// prefer auth quality of protection over auth-int
+	var qop string
+	for _, q := range strings.Split(c["qop"], ",") {
+		q = strings.TrimSpace(q)
+		if q == "auth" || q == "auth-int" && qop == "" {
+			qop = q
+		}
+	}
+
+	nc := "00000001"
+	ha1 := h(user, c["realm"], password)
+	if strings.HasSuffix(algorithm, "-SESS") {
+		ha1 = h(ha1, c["nonce"], cnonce)
+	}
+	ha2 := h(method, uri)
+	if qop == "auth-int" {
+		ha2 = h(method, uri, h(string(body)))
+	}
+
+	var response string
+	if qop == "" {
+		response = h(ha1, c["nonce"], ha2)
+	} else {
+		response = h(ha1, c["nonce"], nc, cnonce, qop, ha2)
+	}
+
+	params := []string{
+		`username="` + user + `"`, `realm="` + c["realm"] + `"`, `nonce="` + c["nonce"] + `"`,
+		`uri="` + uri + `"`, `response="` + response + `"`,
+	}
+	if c["algorithm"] != "" {
+		params = append(params, "algorithm="+c["algorithm"])
+	}
+	if c["opaque"] != "" {
+		params = append(params, `opaque="`+c["opaque"]+`"`)
+	}
+	if qop != "" {
+		params = append(params, "qop="+qop, "nc="+nc, `cnonce="`+cnonce+`"`)
+	}
+	return "Digest " + strings.Join(params, ", ")
+}
+
+// Send request with Digest auth: the first request takes the challenge of server
+// from 401 response, the second one is sent with computed Authorization header.
+func doDigestAuth(cli *http.Client, r *http.Request, a Auth) (*http.Response, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return nil, err
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	res, err := cli.Do(r)
+	if err != nil || res.StatusCode != http.StatusUnauthorized {
+		return res, err
+	}
+	io.Copy(io.Discard, res.Body)
+	res.Body.Close()
+
+	c, err := parseDigestChallenge(res.Header.Get("WWW-Authenticate"))
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, 8)
+	rand.Read(b)
+
+	r2 := r.Clone(r.Context())
+	if body != nil {
+		r2.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	r2.Header.Set("Authorization", digestAuthorization(c, r.Method, r.URL.RequestURI(),
+		a.User, a.Password, hex.EncodeToString(b), body))
+	return cli.Do(r2)
+}
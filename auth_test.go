@@ -0,0 +1,68 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAuth(t *testing.T) {
+	t.Run("apply", func(t *testing.T) {
+		r, _ := http.NewRequest("GET", "http://localhost/?a=1", nil)
+		applyAuth(r, Auth{Type: authBasic, User: "user", Password: "pass"})
+		if u, p, ok := r.BasicAuth(); !ok || u != "user" || p != "pass" {
+			t.Errorf("expected basic auth user:pass, got: %s:%s", u, p)
+		}
+		applyAuth(r, Auth{Type: authBearer, Token: "abc"})
+		if v := r.Header.Get("Authorization"); v != "Bearer abc" {
+			t.Errorf("expected Bearer abc, got: %s", v)
+		}
+		applyAuth(r, Auth{Type: authAPIKeyQuery, Name: "key", Token: "xyz"})
+		if v := r.URL.Query().Get("key"); v != "xyz" {
+			t.Errorf("expected query param key=xyz, got: %s", v)
+		}
+	})
+
+	t.Run("digest", func(t *testing.T) {
+		c := map[string]string{"realm": "test", "nonce": "abc", "qop": "auth"}
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h := r.Header.Get("Authorization")
+			if h == "" {
+				w.Header().Set("WWW-Authenticate", `Digest realm="test", nonce="abc", qop="auth"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			p, _ := parseDigestChallenge(h)
+			if h != digestAuthorization(c, r.Method, r.URL.RequestURI(), "user", "pass", p["cnonce"], nil) {
+				w.WriteHeader(http.StatusForbidden)
+			}
+		}))
+		defer srv.Close()
+
+		r, _ := http.NewRequest("GET", srv.URL+"/secret?a=1", nil)
+		res, err := doDigestAuth(srv.Client(), r, Auth{Type: authDigest, User: "user", Password: "pass"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.StatusCode != http.StatusOK {
+			t.Errorf("expected status 200, got: %d", res.StatusCode)
+		}
+	})
+
+	t.Run("digest challenge", func(t *testing.T) {
+		if _, err := parseDigestChallenge(`Basic realm="test"`); err == nil {
+			t.Error("expected error of not Digest challenge")
+		}
+		c, err := parseDigestChallenge(`Digest realm="a, b", nonce="n", algorithm=SHA-256`)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c["realm"] != "a, b" || c["algorithm"] != "SHA-256" {
+			t.Errorf("expected realm \"a, b\" and algorithm SHA-256, got: %v", c)
+		}
+		if !strings.HasPrefix(digestAuthorization(c, "GET", "/", "u", "p", "x", nil), "Digest ") {
+			t.Error("expected Digest authorization header")
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Types of request body of editor.
+const (
+	bodyJSON = iota
+	bodyXML
+	bodyText
+	bodyYAML
+	bodyNDJSON
+	bodyGraphQL
+	bodyCustom
+
+	bodyTypesEnd
+)
+
+// BodyType is a type of request body: name, content type, placeholder of editor and
+// validator (optional).
+type BodyType struct {
+	Name        string
+	ContentType string
+	Placeholder string
+	Validate    func(string) error
+}
+
+var bodyTypes = [bodyTypesEnd]BodyType{
+	{"JSON", "application/json", `{ "key": "value", ...}`, validateJSON},
+	{"XML", "application/xml", `<root><key>value</key></root>`, validateXML},
+	{"Text", "text/plain", "plain text", nil},
+	{"YAML", "application/yaml", "key: value", nil},
+	{"NDJSON", "application/x-ndjson", `{"key": "value"}` + "\n" + `{"key": "value"}`, validateNDJSON},
+	{"GraphQL", "application/graphql", "query { user(id: 1) { name } }", nil},
+	{"Custom", "", "body of custom content type", nil},
+}
+
+func validateJSON(s string) error {
+	if !json.Valid([]byte(s)) {
+		return errors.New("invalid JSON")
+	}
+	return nil
+}
+
+func validateXML(s string) error {
+	d := xml.NewDecoder(strings.NewReader(s))
+	for {
+		_, err := d.Token()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return errors.New("invalid XML: " + err.Error())
+		}
+	}
+}
+
+func validateNDJSON(s string) error {
+	sc := bufio.NewScanner(strings.NewReader(s))
+	for n := 1; sc.Scan(); n++ {
+		if line := strings.TrimSpace(sc.Text()); line != "" && !json.Valid([]byte(line)) {
+			return errors.New("invalid JSON at line " + strconv.Itoa(n))
+		}
+	}
+	return nil
+}
+
+var (
+	rawPayloadEncoded     string
+	rawPayloadContentType string
+)
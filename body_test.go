@@ -0,0 +1,68 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textarea"
+)
+
+func TestValidateBody(t *testing.T) {
+	for _, tc := range []struct {
+		name     string
+		validate func(string) error
+		body     string
+		valid    bool
+	}{
+		{"xml", validateXML, `<?xml version="1.0"?><user id="1"><name>neo</name></user>`, true},
+		{"xml fragments", validateXML, "<a/><b/>", true},
+		{"xml unclosed", validateXML, "<user><name>neo</user>", false},
+		{"xml bad attribute", validateXML, `<user id=1/>`, false},
+		{"ndjson", validateNDJSON, "{\"id\":1}\n\n[2]\n", true},
+		{"ndjson empty", validateNDJSON, "", true},
+		{"ndjson bad line", validateNDJSON, "{\"id\":1}\n{\"id\":", false},
+		{"ndjson multiline value", validateNDJSON, "{\n\"id\":1}", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.validate(tc.body); (err == nil) != tc.valid {
+				t.Errorf("expected valid %v of %q, got: %v", tc.valid, tc.body, err)
+			}
+		})
+	}
+
+	if err := validateNDJSON("{}\n{\n"); err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("expected error at line 2, got: %v", err)
+	}
+}
+
+func TestEditorView(t *testing.T) {
+	ta := textarea.New()
+	ta.Prompt = ""
+	ta.SetWidth(10) // 4 columns of line numbers
+	ta.SetHeight(3)
+	ta.SetValue("<a>hello</a>\n<b/>")
+
+	if expected := "  1 <a>hel\n    lo</a>\n  2 <b/>"; editorView(ta, "application/xml", 0) != expected {
+		t.Errorf("expected all wrapped lines of blurred editor, got: %q", editorView(ta, "application/xml", 0))
+	}
+
+	ta.SetHeight(2)
+	ta.Focus()
+	top := editorScroll(ta, 0) // cursor is at the end of the third wrapped line
+	if top != 1 {
+		t.Errorf("expected scroll to the cursor line, got: %d", top)
+	}
+	if lines := strings.Split(editorView(ta, "application/xml", top), "\n"); len(lines) != 2 ||
+		lines[0] != "    lo</a>" || !strings.HasPrefix(lines[1], "  2 <b/>") {
+		t.Errorf("expected 2 lines from the second one, got: %q", lines)
+	}
+
+	ta.CursorUp()
+	if top := editorScroll(ta, top); top != 1 {
+		t.Errorf("expected the same scroll of visible cursor line, got: %d", top)
+	}
+	ta.SetValue("")
+	if v := editorView(ta, "application/xml", 0); !strings.Contains(v, ta.Placeholder) {
+		t.Errorf("expected placeholder of empty editor, got: %q", v)
+	}
+}
@@ -56,15 +56,12 @@ func (c *Checkbox) SetOff() {
 }
 
 func (c Checkbox) Update(msg tea.Msg) (Checkbox, tea.Cmd) {
-	switch msg := msg.(type) {
-	case tea.KeyMsg:
-		switch msg.Type {
-		case tea.KeySpace: // toggle checkbox
-			if c.state == on {
-				c.state = off
-			} else {
-				c.state = on
-			}
+	switch msg.(type) {
+	case tea.KeyMsg: // toggle checkbox, the key is matched by key map
+		if c.state == on {
+			c.state = off
+		} else {
+			c.state = on
 		}
 	}
 	cmd := func() tea.Msg {
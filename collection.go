@@ -0,0 +1,289 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CollectionItem is a named request of collection.
+type CollectionItem struct {
+	Name    string  `json:"name"`
+	Session Session `json:"session"`
+}
+
+// CollectionFolder is a named folder of requests and subfolders.
+type CollectionFolder struct {
+	Name     string              `json:"name"`
+	Folders  []*CollectionFolder `json:"folders,omitempty"`
+	Requests []*CollectionItem   `json:"requests,omitempty"`
+}
+
+// Number of requests of folder and its subfolders.
+func (f *CollectionFolder) Count() int {
+	n := len(f.Requests)
+	for _, sub := range f.Folders {
+		n += sub.Count()
+	}
+	return n
+}
+
+// Node of collection tree: a folder or a request, parent is a folder which contains it.
+type collectionNode struct {
+	depth  int
+	parent *CollectionFolder
+	folder *CollectionFolder
+	item   *CollectionItem
+}
+
+func (n *collectionNode) Name() string {
+	if n.folder != nil {
+		return n.folder.Name
+	}
+	return n.item.Name
+}
+
+// Collection is a tree of named saved requests stored in one JSON file,
+// it's supposed to be kept in git along with the project.
+type Collection struct {
+	path   string
+	root   *CollectionFolder // shared by copies of collection, nodes refer to it
+	nodes  []collectionNode  // flatten tree
+	cursor int
+	style  []lipgloss.Style
+
+	pendingDelete *CollectionFolder // folder to delete on confirmation
+	saveKey       string            // help of key saving request to collection
+}
+
+func NewCollection(path string, colors ...lipgloss.Color) Collection {
+	return Collection{
+		path: path,
+		root: &CollectionFolder{},
+		style: []lipgloss.Style{
+			lipgloss.NewStyle().Foreground(colors[0]),
+			lipgloss.NewStyle().Foreground(colors[1]).Bold(true),
+			lipgloss.NewStyle().Foreground(colors[2]).Bold(true),
+		},
+	}
+}
+
+// Load collection from disk, it's ok if collection file is missed.
+func (c *Collection) Load() error {
+	b, err := os.ReadFile(c.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	if err := json.Unmarshal(b, c.root); err != nil {
+		return err
+	}
+	c.flatten()
+	return nil
+}
+
+// Save collection to disk.
+func (c *Collection) Save() error {
+	if c.path == "" {
+		return errors.New("collection file is not configured")
+	}
+	b, err := json.MarshalIndent(c.root, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(c.path, append(b, '\n'), 0o644)
+}
+
+func (c *Collection) flatten() {
+	c.nodes = nil
+	var walk func(f *CollectionFolder, depth int)
+	walk = func(f *CollectionFolder, depth int) {
+		for _, sub := range f.Folders {
+			c.nodes = append(c.nodes, collectionNode{depth: depth, parent: f, folder: sub})
+			walk(sub, depth+1)
+		}
+		for _, item := range f.Requests {
+			c.nodes = append(c.nodes, collectionNode{depth: depth, parent: f, item: item})
+		}
+	}
+	walk(c.root, 0)
+	if c.cursor >= len(c.nodes) {
+		c.cursor = max(len(c.nodes)-1, 0)
+	}
+}
+
+// Selected node.
+func (c *Collection) selected() (collectionNode, bool) {
+	if c.cursor >= len(c.nodes) {
+		return collectionNode{}, false
+	}
+	return c.nodes[c.cursor], true
+}
+
+// Selected request.
+func (c *Collection) Selected() (*CollectionItem, bool) {
+	n, ok := c.selected()
+	if !ok || n.item == nil {
+		return nil, false
+	}
+	return n.item, true
+}
+
+// Name of selected node.
+func (c *Collection) SelectedName() string {
+	n, ok := c.selected()
+	if !ok {
+		return ""
+	}
+	return n.Name()
+}
+
+// Move cursor up.
+func (c *Collection) Up() {
+	c.pendingDelete = nil
+	if c.cursor > 0 {
+		c.cursor--
+	}
+}
+
+// Move cursor down.
+func (c *Collection) Down() {
+	c.pendingDelete = nil
+	if c.cursor+1 < len(c.nodes) {
+		c.cursor++
+	}
+}
+
+// Rename selected folder or request.
+func (c *Collection) Rename(name string) error {
+	n, ok := c.selected()
+	if !ok {
+		return errors.New("nothing is selected")
+	}
+	if n.folder != nil {
+		n.folder.Name = name
+	} else {
+		n.item.Name = name
+	}
+	return c.Save()
+}
+
+// Duplicate selected request.
+func (c *Collection) Duplicate() error {
+	n, ok := c.selected()
+	if !ok || n.item == nil {
+		return errors.New("select a request to duplicate")
+	}
+	b, err := json.Marshal(n.item)
+	if err != nil {
+		return err
+	}
+	dup := &CollectionItem{}
+	if err := json.Unmarshal(b, dup); err != nil {
+		return err
+	}
+	dup.Name += " copy"
+	for i, item := range n.parent.Requests {
+		if item == n.item {
+			n.parent.Requests = append(n.parent.Requests[:i+1],
+				append([]*CollectionItem{dup}, n.parent.Requests[i+1:]...)...)
+			break
+		}
+	}
+	c.flatten()
+	c.cursor++
+	return c.Save()
+}
+
+// Delete selected folder (with all its content) or request. Folder with requests is deleted
+// only on the second call (confirmation), false is returned if it's not deleted yet.
+func (c *Collection) Delete() (bool, error) {
+	n, ok := c.selected()
+	if !ok {
+		return false, errors.New("nothing is selected")
+	}
+	if n.folder != nil && n.folder.Count() > 0 && c.pendingDelete != n.folder {
+		c.pendingDelete = n.folder
+		return false, nil
+	}
+	c.pendingDelete = nil
+	if n.folder != nil {
+		for i, f := range n.parent.Folders {
+			if f == n.folder {
+				n.parent.Folders = append(n.parent.Folders[:i], n.parent.Folders[i+1:]...)
+				break
+			}
+		}
+	} else {
+		for i, item := range n.parent.Requests {
+			if item == n.item {
+				n.parent.Requests = append(n.parent.Requests[:i], n.parent.Requests[i+1:]...)
+				break
+			}
+		}
+	}
+	c.flatten()
+	return true, c.Save()
+}
+
+// Save session to the selected request, if a folder is selected or collection is empty
+// a new request is added to the folder.
+func (c *Collection) Put(ses Session) (string, error) {
+	ses.Request = ses.Request.Clone() // the stored request is not changed by edits of the saved one
+	n, ok := c.selected()
+	switch {
+	case ok && n.item != nil:
+		n.item.Session = ses
+		return n.item.Name, c.Save()
+	case ok:
+		n.parent = n.folder
+	default:
+		n.parent = c.root
+	}
+	return c.add(n.parent, ses)
+}
+
+// Save session as a new request of the root folder, the selected request is kept.
+func (c *Collection) Add(ses Session) (string, error) {
+	ses.Request = ses.Request.Clone()
+	return c.add(c.root, ses)
+}
+
+func (c *Collection) add(f *CollectionFolder, ses Session) (string, error) {
+	name := ses.Request.Method + " " + ses.Request.Host + ses.Request.UrlPath
+	f.Requests = append(f.Requests, &CollectionItem{Name: name, Session: ses})
+	c.flatten()
+	return name, c.Save()
+}
+
+// Render page of tree which contains the cursor.
+func (c Collection) View(width, height int) string {
+	var lines []string
+	limit := max(height, 1)
+	start := c.cursor / limit * limit
+	for i := start; i < len(c.nodes) && i < start+limit; i++ {
+		n := c.nodes[i]
+		style, marker := c.style[0], "  "
+		if i == c.cursor {
+			style, marker = c.style[1], "› "
+		} else if n.folder != nil {
+			style = c.style[2]
+		}
+		line := marker + strings.Repeat("  ", n.depth)
+		if n.folder != nil {
+			line += "▾ " + n.folder.Name
+		} else {
+			line += "  " + n.item.Name
+		}
+		lines = append(lines, style.MaxWidth(width).Render(line))
+	}
+	if len(c.nodes) == 0 {
+		lines = append(lines, c.style[0].Render("collection is empty, save a request: "+c.saveKey))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestCollection(t *testing.T) {
+	newCollection := func(t *testing.T) Collection {
+		c := NewCollection(filepath.Join(t.TempDir(), "collection.json"), make([]lipgloss.Color, 3)...)
+		c.root = &CollectionFolder{
+			Folders: []*CollectionFolder{{
+				Name:     "users",
+				Requests: []*CollectionItem{{Name: "list"}, {Name: "create"}},
+			}},
+			Requests: []*CollectionItem{{Name: "health"}},
+		}
+		c.flatten() // users, list, create, health
+		return c
+	}
+	names := func(c Collection) []string {
+		var s []string
+		for _, n := range c.nodes {
+			s = append(s, n.Name())
+		}
+		return s
+	}
+	ses := Session{Request: Request{
+		Method: "POST", Host: "example.com", UrlPath: "/users", Payload: "json", Body: `{"name":"neo"}`,
+	}}
+
+	t.Run("put", func(t *testing.T) {
+		c := newCollection(t)
+		c.cursor = 0 // folder: a new request is added to it
+		name, err := c.Put(ses)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if name != "POST example.com/users" || len(c.root.Folders[0].Requests) != 3 {
+			t.Errorf("expected a new request of folder, got: %s %v", name, names(c))
+		}
+
+		c.cursor = 1 // request: it's overwritten
+		if name, _ := c.Put(ses); name != "list" || len(c.nodes) != 5 {
+			t.Errorf("expected overwritten request list, got: %s %v", name, names(c))
+		}
+
+		loaded := NewCollection(c.path, make([]lipgloss.Color, 3)...)
+		if err := loaded.Load(); err != nil {
+			t.Fatal(err)
+		}
+		r := loaded.root.Folders[0].Requests[0].Session.Request
+		if r.Payload != "json" || r.Body != `{"name":"neo"}` || payloadType(r.Payload) != jsonPayload {
+			t.Errorf("expected saved JSON payload, got: %s %s", r.Payload, r.Body)
+		}
+	})
+
+	t.Run("add", func(t *testing.T) {
+		c := newCollection(t)
+		c.cursor = 1 // request: it's kept
+		name, err := c.Add(ses)
+		if err != nil {
+			t.Fatal(err)
+		}
+		last := c.nodes[len(c.nodes)-1]
+		if name != "POST example.com/users" || last.item == nil || last.depth != 0 || len(c.nodes) != 5 {
+			t.Errorf("expected a new request of root, got: %s %v", name, names(c))
+		}
+		if n, _ := c.selected(); n.item == nil || n.item.Session.Request.Method == "POST" {
+			t.Errorf("expected selected request is not overwritten, got: %v", names(c))
+		}
+	})
+
+	t.Run("put to empty collection", func(t *testing.T) {
+		c := NewCollection(filepath.Join(t.TempDir(), "collection.json"), make([]lipgloss.Color, 3)...)
+		c.saveKey = "Ctrl+s"
+		if v := c.View(80, 10); !strings.Contains(v, "save a request: Ctrl+s") {
+			t.Errorf("expected hint with key of saving, got: %s", v)
+		}
+		if _, err := c.Put(ses); err != nil || len(c.root.Requests) != 1 {
+			t.Errorf("expected a new request of root, got: %v %v", err, names(c))
+		}
+	})
+
+	t.Run("rename", func(t *testing.T) {
+		c := newCollection(t)
+		c.cursor = 0
+		if err := c.Rename("accounts"); err != nil || c.root.Folders[0].Name != "accounts" {
+			t.Errorf("expected renamed folder, got: %v %v", err, names(c))
+		}
+		c.cursor = 3
+		if err := c.Rename("ping"); err != nil || c.root.Requests[0].Name != "ping" {
+			t.Errorf("expected renamed request, got: %v %v", err, names(c))
+		}
+	})
+
+	t.Run("duplicate", func(t *testing.T) {
+		c := newCollection(t)
+		c.cursor = 0
+		if err := c.Duplicate(); err == nil {
+			t.Error("expected error of duplicate of folder")
+		}
+		c.cursor = 1
+		if err := c.Duplicate(); err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{"users", "list", "list copy", "create", "health"}
+		if got := names(c); !slices.Equal(got, expected) || c.SelectedName() != "list copy" {
+			t.Errorf("expected %v with selected copy, got: %v %s", expected, got, c.SelectedName())
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		c := newCollection(t)
+		c.cursor = 3
+		if deleted, err := c.Delete(); !deleted || err != nil || len(c.root.Requests) != 0 {
+			t.Errorf("expected deleted request, got: %v %v %v", deleted, err, names(c))
+		}
+
+		c.cursor = 0
+		if deleted, _ := c.Delete(); deleted || len(c.root.Folders) != 1 {
+			t.Errorf("expected confirmation of delete of folder, got: %v", names(c))
+		}
+		c.Down()
+		c.Up()
+		if deleted, _ := c.Delete(); deleted {
+			t.Error("expected confirmation is reset by move of cursor")
+		}
+		if deleted, err := c.Delete(); !deleted || err != nil || len(c.nodes) != 0 {
+			t.Errorf("expected deleted folder, got: %v %v %v", deleted, err, names(c))
+		}
+		if _, err := c.Delete(); err == nil {
+			t.Error("expected error of delete of empty collection")
+		}
+	})
+}
+
+func TestCollectionIsolation(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "collection.json")
+	c := NewCollection(path, make([]lipgloss.Color, 3)...)
+	c.root = &CollectionFolder{Requests: []*CollectionItem{
+		{Name: "login", Session: Session{Request: Request{
+			Method: "POST", Host: "example.com", UrlPath: "/login", Payload: "form",
+			Headers:    map[string][]string{"Accept": {"*/*"}},
+			FormValues: map[string][]string{"user": {"neo"}},
+		}}},
+		{Name: "health", Session: Session{Request: Request{Method: "GET", Host: "example.com", UrlPath: "/health"}}},
+	}}
+	c.flatten()
+	if err := c.Save(); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ := json.Marshal(c.root.Requests[0])
+
+	// load the first item and edit it without saving
+	item, _ := c.Selected()
+	req := item.Session.Request.NewHTTPRequest()
+	form := cloneValues(item.Session.Request.FormValues)
+	req.Header.Set("Accept", "text/plain")
+	req.Header.Set("X-Debug", "1")
+	form["user"][0] = "trinity"
+
+	// save the edited request as the second item
+	c.Down()
+	ses, _ := NewSession(req, nil, 0, "", form, nil)
+	if _, err := c.Put(*ses); err != nil {
+		t.Fatal(err)
+	}
+	loaded := NewCollection(path, make([]lipgloss.Color, 3)...)
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := json.Marshal(loaded.root.Requests[0]); !bytes.Equal(b, saved) {
+		t.Errorf("expected the first item on disk is unchanged, got: %s", b)
+	}
+	if r := c.root.Requests[0].Session.Request; r.Headers["Accept"][0] != "*/*" || len(r.Headers) != 1 ||
+		r.FormValues["user"][0] != "neo" {
+		t.Errorf("expected the first item is untouched by edits, got: %v %v", r.Headers, r.FormValues)
+	}
+
+	// edits after saving do not change the saved item
+	req.Header.Set("Accept", "application/json")
+	form["user"][0] = "morpheus"
+	r := c.root.Requests[1].Session.Request
+	if r.Headers["Accept"][0] != "text/plain" || r.FormValues["user"][0] != "trinity" {
+		t.Errorf("expected the saved item is not changed by later edits, got: %v %v", r.Headers, r.FormValues)
+	}
+}
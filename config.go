@@ -6,8 +6,11 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/alecthomas/chroma/v2/styles"
 	"github.com/charmbracelet/lipgloss"
 )
 
@@ -16,9 +19,13 @@ var defaultConfig []byte
 
 // Config of rHtttp.
 type Config struct {
-	Settings `json:"Settings"`
-	Theme    `json:"Theme"`
-	Warnings []string // todo show warnings to user
+	Settings     `json:"Settings"`
+	Theme        `json:"Theme"`
+	Environments map[string]Environment `json:"Environments"`
+	Signers      map[string]Signer      `json:"Signers"`
+	Keys         map[string][]string    `json:"Keys"` // keys of actions of key map
+	KeyMap       KeyMap                 `json:"-"`
+	Warnings     []string               // shown in the status bar on launch
 }
 
 // Settings: default checkbox state, full screen mode etc.
@@ -26,6 +33,13 @@ type Settings struct {
 	Timeout      int             `json:"Timeout"`
 	MaxRedirects int             `json:"MaxRedirects"`
 	Checkboxes   map[string]bool `json:"Checkboxes"`
+	Environment  string          `json:"Environment"`
+	History      string          `json:"History"`
+	HistorySize  int             `json:"HistorySize"` // max number of history entries, 0 means no limit
+	Collection   string          `json:"Collection"`
+	CookieJar    string          `json:"CookieJar"`
+	Proxy        Proxy           `json:"Proxy"`
+	TLS          TLS             `json:"TLS"`
 }
 
 // UI color settings.
@@ -43,9 +57,11 @@ func (c *Config) WarningMessage() string {
 	return strings.Join(c.Warnings, ", ")
 }
 
-// Add error.
+// Add warning, duplicates are skipped.
 func (c *Config) AddWarn(warn string) {
-	c.Warnings = append(c.Warnings, warn)
+	if !slices.Contains(c.Warnings, warn) {
+		c.Warnings = append(c.Warnings, warn)
+	}
 }
 
 // Color of item, write to stderr a message if requested color is not found in theme.
@@ -96,11 +112,16 @@ func loadOverrideSettings(path string, c *Config) error {
 		return nil // no config path passed
 	}
 
+	return loadJSON(expandHome(path), c)
+}
+
+// Expand ~/ of path to user home.
+func expandHome(path string) string {
 	if strings.HasPrefix(path, "~/") {
 		userHome, _ := os.UserHomeDir() // ignore rare cases when user home is undefined
-		path = filepath.Join(userHome, path)
+		path = filepath.Join(userHome, path[2:])
 	}
-	return loadJSON(path, c)
+	return path
 }
 
 func loadJSON(path string, c *Config) error {
@@ -113,9 +134,111 @@ func loadJSON(path string, c *Config) error {
 	if err != nil {
 		return err
 	}
+	return c.checkUnknownKeys(b)
+}
+
+// Sections of config with arbitrary keys.
+var freeFormConfigKeys = []string{"Environments", "Signers", "Keys"}
+
+// Add warnings about keys of config which are not in the default config.
+func (c *Config) checkUnknownKeys(b []byte) error {
+	var conf, defaults map[string]any
+	if err := json.Unmarshal(b, &conf); err != nil {
+		return err
+	}
+	if err := json.Unmarshal(defaultConfig, &defaults); err != nil {
+		return err
+	}
+
+	var walk func(prefix string, conf, defaults map[string]any)
+	walk = func(prefix string, conf, defaults map[string]any) {
+		var names []string
+		for name := range conf {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			dv, ok := defaults[name]
+			switch {
+			case !ok:
+				c.AddWarn(`unknown config key "` + prefix + name + `"`)
+			case prefix == "" && slices.Contains(freeFormConfigKeys, name):
+			default:
+				d, dOk := dv.(map[string]any)
+				v, vOk := conf[name].(map[string]any)
+				if dOk && vOk {
+					walk(prefix+name+".", v, d)
+				}
+			}
+		}
+	}
+	walk("", conf, defaults)
 	return nil
 }
 
+// Check if color is an ANSI color (0-255) or a hex one (#RGB or #RRGGBB).
+func validColor(s string) bool {
+	if h, ok := strings.CutPrefix(s, "#"); ok {
+		_, err := strconv.ParseUint(h, 16, 32)
+		return err == nil && (len(h) == 3 || len(h) == 6)
+	}
+	n, err := strconv.Atoi(s)
+	return err == nil && n >= 0 && n <= 255
+}
+
+// Validate settings and theme: timeout and max redirects, chroma style, colors
+// and emojis (all of the default config should be set), problems are added as warnings.
+func (c *Config) Validate() {
+	if c.Timeout < 0 {
+		c.AddWarn("negative Timeout: " + strconv.Itoa(c.Timeout))
+	}
+	if c.MaxRedirects < 0 {
+		c.AddWarn("negative MaxRedirects: " + strconv.Itoa(c.MaxRedirects))
+	}
+	if c.HistorySize < 0 {
+		c.AddWarn("negative HistorySize: " + strconv.Itoa(c.HistorySize))
+	}
+	if _, ok := styles.Registry[c.Chroma]; !ok {
+		c.AddWarn(`unknown chroma style "` + c.Chroma + `"`)
+	}
+
+	var defaults Config
+	json.Unmarshal(defaultConfig, &defaults)
+
+	var names []string
+	for name := range c.Colors {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if !validColor(string(c.Colors[name])) {
+			c.AddWarn(`invalid color "` + name + `": "` + string(c.Colors[name]) + `"`)
+		}
+	}
+
+	names = nil
+	for name := range defaults.Colors {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if _, ok := c.Colors[name]; !ok {
+			c.AddWarn(`color "` + name + `" not found`)
+		}
+	}
+
+	names = nil
+	for name := range defaults.Emojis {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		if c.Emojis[name] == "" {
+			c.AddWarn(`emoji "` + name + `" not found`)
+		}
+	}
+}
+
 // Read config.
 func ReadConfig(configPath string) (*Config, error) {
 	c := &Config{}
@@ -132,6 +255,13 @@ func ReadConfig(configPath string) (*Config, error) {
 		return nil, err
 	}
 
+	c.Validate()
+	var warns []string
+	c.KeyMap, warns = NewKeyMap(c.Keys)
+	for _, w := range warns {
+		c.AddWarn(w)
+	}
+
 	return c, nil
 }
 
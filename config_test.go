@@ -0,0 +1,54 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestReadConfig(t *testing.T) {
+	t.Setenv("HOME", t.TempDir()) // no user config
+
+	t.Run("default", func(t *testing.T) {
+		c, err := ReadConfig("")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.HasWarnings() {
+			t.Errorf("expected no warnings of default config, got: %s", c.WarningMessage())
+		}
+	})
+
+	t.Run("warnings", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "config.json")
+		err := os.WriteFile(path, []byte(`{
+			"Settings": {"Timeout": -1, "MaxRedirects": -2, "Histroy": "", "Proxy": {"HTTP": "", "Socks": ""}},
+			"Environments": {"dev": {"token": "abc"}},
+			"Theme": {"Chroma": "nope", "Colors": {"url": "#12345", "helpKey": "256", "headerName": "#fff"},
+				"Emojis": {"statusbarToken": ""}},
+			"Colours": {}
+		}`), 0o600)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c, err := ReadConfig(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{
+			`unknown config key "Colours"`,
+			`unknown config key "Settings.Histroy"`,
+			`unknown config key "Settings.Proxy.Socks"`,
+			"negative Timeout: -1",
+			"negative MaxRedirects: -2",
+			`unknown chroma style "nope"`,
+			`invalid color "helpKey": "256"`,
+			`invalid color "url": "#12345"`,
+			`emoji "statusbarToken" not found`,
+		}
+		if !slices.Equal(c.Warnings, expected) {
+			t.Errorf("expected warnings:\n%q\ngot:\n%q", expected, c.Warnings)
+		}
+	})
+}
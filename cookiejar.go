@@ -0,0 +1,409 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"golang.org/x/net/publicsuffix"
+)
+
+// JarCookie is a cookie of jar with its attributes.
+type JarCookie struct {
+	Name     string    `json:"name"`
+	Value    string    `json:"value"`
+	Domain   string    `json:"domain"`
+	Path     string    `json:"path"`
+	Expires  time.Time `json:"expires"` // zero is a session cookie
+	Secure   bool      `json:"secure,omitempty"`
+	HttpOnly bool      `json:"httpOnly,omitempty"`
+	HostOnly bool      `json:"hostOnly,omitempty"` // sent to the exact host only, not to subdomains
+	SameSite string    `json:"sameSite,omitempty"`
+}
+
+var sameSiteNames = map[http.SameSite]string{
+	http.SameSiteLaxMode: "Lax", http.SameSiteStrictMode: "Strict", http.SameSiteNoneMode: "None",
+}
+
+// Create cookie of jar from cookie of response sent by the given URL,
+// false is returned if the cookie is expired (it means deletion of cookie).
+func newJarCookie(u *url.URL, c *http.Cookie, now time.Time) (JarCookie, bool) {
+	jc := JarCookie{
+		Name: c.Name, Value: c.Value, Path: c.Path, Secure: c.Secure, HttpOnly: c.HttpOnly,
+		SameSite: sameSiteNames[c.SameSite],
+	}
+	jc.Domain = strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	if jc.Domain == "" {
+		jc.Domain = strings.ToLower(u.Hostname())
+		jc.HostOnly = true
+	}
+	if jc.Path == "" || jc.Path[0] != '/' {
+		jc.Path = defaultCookiePath(u)
+	}
+	switch {
+	case c.MaxAge < 0:
+		return jc, false
+	case c.MaxAge > 0:
+		jc.Expires = now.Add(time.Duration(c.MaxAge) * time.Second)
+	case !c.Expires.IsZero():
+		jc.Expires = c.Expires
+	}
+	return jc, !jc.expired(now)
+}
+
+// Parse cookie in format of Set-Cookie header.
+func parseSetCookie(s string) (*http.Cookie, error) {
+	cs := (&http.Response{Header: http.Header{"Set-Cookie": {s}}}).Cookies()
+	if len(cs) == 0 {
+		return nil, errors.New("invalid cookie: " + s)
+	}
+	return cs[0], nil
+}
+
+// Default path of cookie: the directory of URL path.
+func defaultCookiePath(u *url.URL) string {
+	i := strings.LastIndex(u.Path, "/")
+	if i <= 0 || u.Path[0] != '/' {
+		return "/"
+	}
+	return u.Path[:i]
+}
+
+func (c *JarCookie) expired(now time.Time) bool {
+	return !c.Expires.IsZero() && !c.Expires.After(now)
+}
+
+// Check if host is the domain or its subdomain, IP addresses match exactly only.
+func domainMatch(host, domain string) bool {
+	return host == domain ||
+		strings.HasSuffix(host, "."+domain) && net.ParseIP(host) == nil
+}
+
+// Check if domain is a public suffix (com, co.uk etc), IP addresses are not.
+func isPublicSuffix(domain string) bool {
+	if net.ParseIP(domain) != nil {
+		return false
+	}
+	ps, _ := publicsuffix.PublicSuffix(domain)
+	return ps == domain
+}
+
+// Check if request path is the path of cookie or it's below the one.
+func pathMatch(reqPath, path string) bool {
+	if reqPath == "" {
+		reqPath = "/"
+	}
+	return reqPath == path || strings.HasPrefix(reqPath, path) &&
+		(strings.HasSuffix(path, "/") || reqPath[len(path)] == '/')
+}
+
+// Check if cookie should be sent with request to the given URL.
+func (c *JarCookie) matches(u *url.URL) bool {
+	host := strings.ToLower(u.Hostname())
+	if c.HostOnly && host != c.Domain || !domainMatch(host, c.Domain) {
+		return false
+	}
+	if c.Secure && u.Scheme != "https" && u.Scheme != "wss" {
+		return false
+	}
+	return pathMatch(u.Path, c.Path)
+}
+
+// Flags of cookie separated by space.
+func (c *JarCookie) Flags() string {
+	var flags []string
+	if c.Secure {
+		flags = append(flags, "Secure")
+	}
+	if c.HttpOnly {
+		flags = append(flags, "HttpOnly")
+	}
+	if c.HostOnly {
+		flags = append(flags, "HostOnly")
+	}
+	if c.SameSite != "" {
+		flags = append(flags, "SameSite="+c.SameSite)
+	}
+	return strings.Join(flags, " ")
+}
+
+// Format cookie as value of Set-Cookie header, domain is omitted for host only cookies.
+func (c JarCookie) String() string {
+	hc := http.Cookie{
+		Name: c.Name, Value: c.Value, Path: c.Path, Expires: c.Expires,
+		Secure: c.Secure, HttpOnly: c.HttpOnly,
+	}
+	if !c.HostOnly {
+		hc.Domain = c.Domain
+	}
+	for mode, name := range sameSiteNames {
+		if name == c.SameSite {
+			hc.SameSite = mode
+		}
+	}
+	return hc.String()
+}
+
+// CookieJar keeps cookies of responses per environment and sends them with requests,
+// it's stored on disk as JSON.
+type CookieJar struct {
+	mu      sync.Mutex
+	path    string
+	cookies map[string][]JarCookie // cookies by environment, "" is no environment
+	cursor  int
+	style   []lipgloss.Style
+}
+
+var (
+	jar          *CookieJar
+	useCookieJar bool // send and store cookies of jar
+)
+
+func NewCookieJar(path string, colors ...lipgloss.Color) *CookieJar {
+	return &CookieJar{
+		path:    path,
+		cookies: make(map[string][]JarCookie),
+		style: []lipgloss.Style{
+			lipgloss.NewStyle().Foreground(colors[0]),
+			lipgloss.NewStyle().Foreground(colors[1]).Bold(true),
+		},
+	}
+}
+
+// Load cookies from disk, it's ok if the file is missed.
+func (j *CookieJar) Load() error {
+	if j.path == "" {
+		return nil // jar is not persisted
+	}
+	b, err := os.ReadFile(j.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return json.Unmarshal(b, &j.cookies)
+}
+
+// Save cookies on disk, expired cookies are dropped.
+func (j *CookieJar) Save() error {
+	if j.path == "" {
+		return nil // jar is not persisted
+	}
+	j.mu.Lock()
+	now := time.Now()
+	for env := range j.cookies {
+		j.cookies[env] = slices.DeleteFunc(j.cookies[env], func(c JarCookie) bool {
+			return c.expired(now)
+		})
+	}
+	b, err := json.MarshalIndent(j.cookies, "", "  ")
+	j.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(j.path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(j.path, b, 0o600)
+}
+
+// Put cookie to jar of environment, the cookie with the same name, domain and path is replaced.
+// Cookies are kept sorted by domain, path and name.
+func (j *CookieJar) put(env string, c JarCookie, keep bool) {
+	cookies := j.cookies[env]
+	i, found := slices.BinarySearchFunc(cookies, c, func(a, b JarCookie) int {
+		return cmp.Or(cmp.Compare(a.Domain, b.Domain), cmp.Compare(a.Path, b.Path), cmp.Compare(a.Name, b.Name))
+	})
+	switch {
+	case found && keep:
+		cookies[i] = c
+	case found:
+		cookies = slices.Delete(cookies, i, i+1)
+	case keep:
+		cookies = slices.Insert(cookies, i, c)
+	}
+	j.cookies[env] = cookies
+	j.cursor = min(j.cursor, max(len(cookies)-1, 0))
+}
+
+// Store cookies of response of the given URL to jar of environment.
+func (j *CookieJar) SetCookies(env string, u *url.URL, cookies []*http.Cookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	for _, c := range cookies {
+		jc, keep := newJarCookie(u, c, now)
+		host := strings.ToLower(u.Hostname())
+		if !domainMatch(host, jc.Domain) {
+			continue // cookie of foreign domain
+		}
+		if !jc.HostOnly && isPublicSuffix(jc.Domain) {
+			if host != jc.Domain {
+				continue // cookie of all sites of public suffix
+			}
+			jc.HostOnly = true // as net/http/cookiejar does
+		}
+		j.put(env, jc, keep)
+	}
+}
+
+// Cookies of jar of environment to send with request to the given URL,
+// cookies with longer paths are listed first.
+func (j *CookieJar) Cookies(env string, u *url.URL) []*http.Cookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	now := time.Now()
+	var matched []JarCookie
+	for _, c := range j.cookies[env] {
+		if !c.expired(now) && c.matches(u) {
+			matched = append(matched, c)
+		}
+	}
+	slices.SortStableFunc(matched, func(a, b JarCookie) int {
+		return cmp.Compare(len(b.Path), len(a.Path))
+	})
+	var cookies []*http.Cookie
+	for _, c := range matched {
+		cookies = append(cookies, &http.Cookie{Name: c.Name, Value: c.Value})
+	}
+	return cookies
+}
+
+// Jar of environment implementing [http.CookieJar].
+type envJar struct {
+	jar *CookieJar
+	env string
+}
+
+func (e envJar) SetCookies(u *url.URL, cookies []*http.Cookie) {
+	e.jar.SetCookies(e.env, u, cookies)
+}
+
+func (e envJar) Cookies(u *url.URL) []*http.Cookie {
+	return e.jar.Cookies(e.env, u)
+}
+
+// Jar of environment for [http.Client].
+func (j *CookieJar) For(env string) http.CookieJar {
+	return envJar{j, env}
+}
+
+// Cookies of environment.
+func (j *CookieJar) List(env string) []JarCookie {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return slices.Clone(j.cookies[env])
+}
+
+// Replace cookies of environment.
+func (j *CookieJar) Replace(env string, cookies []JarCookie) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.cookies[env] = nil
+	for _, c := range cookies {
+		j.put(env, c, true)
+	}
+}
+
+// Selected cookie of environment.
+func (j *CookieJar) Selected(env string) (JarCookie, bool) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.cursor >= len(j.cookies[env]) {
+		return JarCookie{}, false
+	}
+	return j.cookies[env][j.cursor], true
+}
+
+// Delete selected cookie of environment.
+func (j *CookieJar) Delete(env string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.cursor < len(j.cookies[env]) {
+		j.put(env, j.cookies[env][j.cursor], false)
+	}
+}
+
+// Replace selected cookie of environment by the given one in format of Set-Cookie header,
+// if nothing is selected the cookie is added. The domain and path of selected cookie
+// (or the given URL) are the defaults of new one.
+func (j *CookieJar) Edit(env string, u *url.URL, s string) error {
+	hc, err := parseSetCookie(s)
+	if err != nil {
+		return err
+	}
+	if c, ok := j.Selected(env); ok {
+		u = &url.URL{Scheme: "https", Host: c.Domain, Path: c.Path + "/"}
+	}
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.cursor < len(j.cookies[env]) {
+		j.put(env, j.cookies[env][j.cursor], false)
+	}
+	jc, keep := newJarCookie(u, hc, time.Now())
+	j.put(env, jc, keep)
+	return nil
+}
+
+// Move cursor up.
+func (j *CookieJar) Up() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.cursor > 0 {
+		j.cursor--
+	}
+}
+
+// Move cursor down.
+func (j *CookieJar) Down(env string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.cursor+1 < len(j.cookies[env]) {
+		j.cursor++
+	}
+}
+
+// Render page of cookies of environment which contains the cursor.
+func (j *CookieJar) View(env string, width, height int) string {
+	cookies := j.List(env)
+	name := env
+	if name == "" {
+		name = "no environment"
+	}
+	lines := []string{headerNameStyle.Render("Cookie jar: ") + headerValueStyle.Render(name)}
+	limit := max(height-1, 1)
+	start := j.cursor / limit * limit
+	for i := start; i < len(cookies) && i < start+limit; i++ {
+		c := cookies[i]
+		expires := "session"
+		if !c.Expires.IsZero() {
+			expires = c.Expires.Local().Format("2006-01-02 15:04")
+		}
+		style, marker := j.style[0], "  "
+		if i == j.cursor {
+			style, marker = j.style[1], "› "
+		}
+		line := marker + c.Domain + " " + c.Path + " " + c.Name + "=" + c.Value + " " + expires
+		if flags := c.Flags(); flags != "" {
+			line += " " + flags
+		}
+		lines = append(lines, style.MaxWidth(width).Render(line))
+	}
+	if len(cookies) == 0 {
+		lines = append(lines, j.style[0].Render("cookie jar is empty"))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
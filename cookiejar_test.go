@@ -0,0 +1,120 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestCookieJar(t *testing.T) {
+	colors := []lipgloss.Color{"1", "2"}
+	origin, _ := url.Parse("https://api.example.com/v1/users")
+
+	t.Run("matching", func(t *testing.T) {
+		j := NewCookieJar("", colors...)
+		j.SetCookies("", origin, []*http.Cookie{
+			{Name: "host", Value: "1"},
+			{Name: "domain", Value: "2", Domain: ".example.com", Path: "/"},
+			{Name: "secure", Value: "3", Path: "/v1", Secure: true},
+			{Name: "foreign", Value: "4", Domain: "other.com"},
+		})
+		for rawURL, expected := range map[string]string{
+			"https://api.example.com/v1/users/1": "host=1 secure=3 domain=2",
+			"http://api.example.com/v1":          "host=1 domain=2",
+			"https://www.example.com/v1":         "domain=2",
+			"https://api.example.com/v2":         "domain=2",
+			"https://other.com/":                 "",
+		} {
+			u, _ := url.Parse(rawURL)
+			var cookies []string
+			for _, c := range j.Cookies("", u) {
+				cookies = append(cookies, c.String())
+			}
+			if v := strings.Join(cookies, " "); v != expected {
+				t.Errorf("expected cookies %q of %s, got: %q", expected, rawURL, v)
+			}
+		}
+		if v := j.Cookies("dev", origin); len(v) > 0 {
+			t.Errorf("expected no cookies of other environment, got: %v", v)
+		}
+	})
+
+	t.Run("public suffix", func(t *testing.T) {
+		j := NewCookieJar("", colors...)
+		j.SetCookies("", origin, []*http.Cookie{{Name: "com", Value: "1", Domain: "com"}})
+		u, _ := url.Parse("https://shop.co.uk/")
+		j.SetCookies("", u, []*http.Cookie{{Name: "uk", Value: "2", Domain: ".co.uk"}})
+		if v := j.List(""); len(v) > 0 {
+			t.Errorf("expected cookies of public suffix are rejected, got: %v", v)
+		}
+
+		u, _ = url.Parse("https://localhost/")
+		j.SetCookies("", u, []*http.Cookie{{Name: "sid", Value: "3", Domain: "localhost"}})
+		if c, ok := j.Selected(""); !ok || !c.HostOnly {
+			t.Errorf("expected host-only cookie of the same host, got: %v", c)
+		}
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		j := NewCookieJar("", colors...)
+		j.SetCookies("", origin, []*http.Cookie{{Name: "sid", Value: "1", MaxAge: 60}})
+		if c, ok := j.Selected(""); !ok || c.Expires.IsZero() {
+			t.Errorf("expected cookie with expiry, got: %v", c)
+		}
+		j.SetCookies("", origin, []*http.Cookie{{Name: "sid", MaxAge: -1}})
+		if v := j.List(""); len(v) > 0 {
+			t.Errorf("expected deleted cookie, got: %v", v)
+		}
+		j.SetCookies("", origin, []*http.Cookie{{Name: "old", Expires: time.Now().Add(-time.Hour)}})
+		if v := j.List(""); len(v) > 0 {
+			t.Errorf("expected expired cookie is not stored, got: %v", v)
+		}
+	})
+
+	t.Run("edit", func(t *testing.T) {
+		j := NewCookieJar("", colors...)
+		j.SetCookies("", origin, []*http.Cookie{{Name: "sid", Value: "1"}})
+		if err := j.Edit("", nil, "sid=2; Path=/; HttpOnly; SameSite=Lax"); err != nil {
+			t.Fatal(err)
+		}
+		c, _ := j.Selected("")
+		if c.Value != "2" || c.Domain != "api.example.com" || c.Path != "/" || !c.HostOnly ||
+			c.Flags() != "HttpOnly HostOnly SameSite=Lax" {
+			t.Errorf("expected edited host only cookie, got: %+v", c)
+		}
+		if s := c.String(); s != "sid=2; Path=/; HttpOnly; SameSite=Lax" {
+			t.Errorf("expected Set-Cookie string, got: %s", s)
+		}
+		j.Delete("")
+		if err := j.Edit("", origin, "lang=en"); err != nil {
+			t.Fatal(err)
+		}
+		if c, _ := j.Selected(""); c.Name != "lang" || c.Domain != "api.example.com" || c.Path != "/v1" {
+			t.Errorf("expected added cookie, got: %+v", c)
+		}
+		if err := j.Edit("", origin, "invalid"); err == nil {
+			t.Error("expected error of invalid cookie")
+		}
+	})
+
+	t.Run("persistence", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "rhttp", "cookies.json")
+		j := NewCookieJar(path, colors...)
+		j.SetCookies("dev", origin, []*http.Cookie{{Name: "sid", Value: "1", MaxAge: 60}})
+		if err := j.Save(); err != nil {
+			t.Fatal(err)
+		}
+		loaded := NewCookieJar(path, colors...)
+		if err := loaded.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if v := loaded.Cookies("dev", origin); len(v) != 1 || v[0].String() != "sid=1" {
+			t.Errorf("expected loaded cookie sid=1, got: %v", v)
+		}
+	})
+}
@@ -0,0 +1,416 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// Curl is a request parsed from curl command line.
+type Curl struct {
+	Request
+	Payload  int    // type of payload: nothing, formPayload, multipartPayload, jsonPayload or rawPayload
+	JSON     string // JSON payload
+	Raw      string // raw payload, its content type is the Content-Type header
+	Warnings []string
+}
+
+// Options of curl which have no value.
+var curlFlags = map[string]bool{
+	"-s": true, "--silent": true, "-S": true, "--show-error": true, "-L": true, "--location": true,
+	"-i": true, "--include": true, "-v": true, "--verbose": true, "-g": true, "--globoff": true,
+	"-f": true, "--fail": true, "--http1.1": true, "--http2": true, "-#": true, "--progress-bar": true,
+	"-N": true, "--no-buffer": true, "--compressed": true, "-k": true, "--insecure": true,
+	"-G": true, "--get": true, "-I": true, "--head": true, "--digest": true, "--basic": true,
+}
+
+// Options of curl which have a value.
+var curlOptions = map[string]bool{
+	"-X": true, "--request": true, "-H": true, "--header": true, "-d": true, "--data": true,
+	"--data-ascii": true, "--data-binary": true, "--data-raw": true, "--json": true,
+	"--data-urlencode": true, "-F": true, "--form": true, "--form-string": true, "-b": true,
+	"--cookie": true, "-u": true, "--user": true, "-A": true, "--user-agent": true, "-e": true,
+	"--referer": true, "--url": true, "-x": true, "--proxy": true, "--noproxy": true,
+	"--cacert": true, "-E": true, "--cert": true, "--key": true,
+}
+
+// Options of curl which have a value but do not affect the request itself.
+var curlIgnoredOptions = map[string]bool{
+	"-o": true, "--output": true, "-m": true, "--max-time": true, "--connect-timeout": true,
+	"-w": true, "--write-out": true, "--retry": true, "--max-redirs": true, "-c": true,
+	"--cookie-jar": true, "-D": true, "--dump-header": true,
+}
+
+// Split command line to words like the shell does: quotes, escapes and line continuations.
+func splitShellWords(s string) ([]string, error) {
+	var (
+		words   []string
+		word    strings.Builder
+		inWord  bool
+		escapes = map[byte]string{'n': "\n", 't': "\t", 'r': "\r", '\\': `\`, '\'': "'", '"': `"`}
+	)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == '\\' && i+1 < len(s):
+			i++
+			if s[i] != '\n' && s[i] != '\r' { // line continuation
+				word.WriteByte(s[i])
+				inWord = true
+			} else if s[i] == '\r' && i+1 < len(s) && s[i+1] == '\n' {
+				i++
+			}
+		case c == '\'':
+			end := strings.IndexByte(s[i+1:], '\'')
+			if end < 0 {
+				return nil, errors.New("unterminated single quote")
+			}
+			word.WriteString(s[i+1 : i+1+end])
+			i += end + 1
+			inWord = true
+		case c == '$' && i+1 < len(s) && s[i+1] == '\'': // ANSI-C quoting: $'...'
+			i += 2
+			for ; i < len(s) && s[i] != '\''; i++ {
+				if s[i] == '\\' && i+1 < len(s) {
+					i++
+					if e, ok := escapes[s[i]]; ok {
+						word.WriteString(e)
+					} else {
+						word.WriteByte('\\')
+						word.WriteByte(s[i])
+					}
+					continue
+				}
+				word.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, errors.New("unterminated single quote")
+			}
+			inWord = true
+		case c == '"':
+			i++
+			for ; i < len(s) && s[i] != '"'; i++ {
+				if s[i] == '\\' && i+1 < len(s) && strings.IndexByte("\"\\$`\n", s[i+1]) >= 0 {
+					i++
+					if s[i] == '\n' {
+						continue
+					}
+				}
+				word.WriteByte(s[i])
+			}
+			if i >= len(s) {
+				return nil, errors.New("unterminated double quote")
+			}
+			inWord = true
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteByte(c)
+			inWord = true
+		}
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
+
+// Read value of curl data option, the @file is replaced by the file content.
+func curlData(v string, stripNewlines bool) (string, error) {
+	if !strings.HasPrefix(v, "@") {
+		return v, nil
+	}
+	b, err := os.ReadFile(strings.TrimPrefix(v, "@"))
+	if err != nil {
+		return "", err
+	}
+	if stripNewlines {
+		return strings.NewReplacer("\r", "", "\n", "").Replace(string(b)), nil
+	}
+	return string(b), nil
+}
+
+// Encode value of --data-urlencode option.
+func curlDataUrlencode(v string) (string, error) {
+	name, content, found := strings.Cut(v, "=")
+	if !found {
+		if n, file, ok := strings.Cut(v, "@"); ok { // name@file or @file
+			b, err := os.ReadFile(file)
+			if err != nil {
+				return "", err
+			}
+			if n == "" {
+				return url.QueryEscape(string(b)), nil
+			}
+			return n + "=" + url.QueryEscape(string(b)), nil
+		}
+		return url.QueryEscape(v), nil
+	}
+	if name == "" {
+		return url.QueryEscape(content), nil
+	}
+	return name + "=" + url.QueryEscape(content), nil
+}
+
+// Parse urlencoded form body, it's not a form if it cannot be encoded back as is:
+// values without "=", separators ";" etc.
+func parseFormBody(body string) (url.Values, bool) {
+	v, err := url.ParseQuery(body)
+	if err != nil {
+		return nil, false
+	}
+	for _, pair := range strings.Split(body, "&") {
+		if !strings.Contains(pair, "=") {
+			return nil, false
+		}
+	}
+	return v, true
+}
+
+// Parse curl command line.
+func ParseCurl(cmd string) (*Curl, error) {
+	words, err := splitShellWords(strings.TrimSpace(cmd))
+	if err != nil {
+		return nil, err
+	}
+	if len(words) == 0 || words[0] != "curl" {
+		return nil, errors.New("not a curl command")
+	}
+
+	c := &Curl{
+		Request: Request{
+			Headers:    make(map[string][]string),
+			FormValues: make(map[string][]string),
+		},
+	}
+	var (
+		rawURL, method, user  string
+		data, cookies         []string
+		getData, head, digest bool
+	)
+
+	args := words[1:]
+	for i := 0; i < len(args); i++ {
+		opt, val, inline := args[i], "", false
+		if !strings.HasPrefix(opt, "-") || opt == "-" {
+			rawURL = opt
+			continue
+		}
+
+		// --opt=value
+		if strings.HasPrefix(opt, "--") {
+			if o, v, ok := strings.Cut(opt, "="); ok {
+				opt, val, inline = o, v, true
+			}
+		} else if len(opt) > 2 && !curlFlags[opt] {
+			// combined short flags: -sSL or short option with value: -XPOST
+			if curlFlags[opt[:2]] && !strings.ContainsFunc(opt[1:], func(r rune) bool {
+				return !curlFlags["-"+string(r)]
+			}) {
+				for _, r := range opt[1:] {
+					switch r {
+					case 'k':
+						c.Insecure = true
+					case 'G':
+						getData = true
+					case 'I':
+						head = true
+					}
+				}
+				continue
+			}
+			opt, val = opt[:2], opt[2:]
+		}
+
+		if !curlOptions[opt] && !curlIgnoredOptions[opt] && !curlFlags[opt] {
+			// unknown options are considered as options without value
+			c.Warnings = append(c.Warnings, "unsupported option: "+opt)
+			continue
+		}
+
+		if curlFlags[opt] {
+			switch opt {
+			case "-k", "--insecure":
+				c.Insecure = true
+			case "-G", "--get":
+				getData = true
+			case "-I", "--head":
+				head = true
+			case "--digest":
+				digest = true
+			}
+			continue
+		}
+
+		if val == "" && !inline {
+			if i+1 >= len(args) {
+				return nil, errors.New("option " + opt + " requires a value")
+			}
+			i++
+			val = args[i]
+		}
+
+		switch opt {
+		case "-X", "--request":
+			method = strings.ToUpper(val)
+		case "-H", "--header":
+			name, v, _ := strings.Cut(val, ":")
+			name = http.CanonicalHeaderKey(strings.TrimSpace(name))
+			v = strings.TrimSpace(v)
+			if name != "" && v != "" {
+				c.Headers[name] = append(c.Headers[name], v)
+			}
+		case "-d", "--data", "--data-ascii", "--data-binary", "--data-raw", "--json":
+			if opt != "--data-raw" {
+				val, err = curlData(val, opt != "--data-binary" && opt != "--json")
+				if err != nil {
+					return nil, err
+				}
+			}
+			if opt == "--json" {
+				c.Headers["Content-Type"] = []string{"application/json"}
+				c.Headers["Accept"] = []string{"application/json"}
+			}
+			data = append(data, val)
+		case "--data-urlencode":
+			val, err = curlDataUrlencode(val)
+			if err != nil {
+				return nil, err
+			}
+			data = append(data, val)
+		case "-F", "--form":
+			name, v, _ := strings.Cut(val, "=")
+			if strings.HasPrefix(v, "<") { // text field with content of file
+				b, err := os.ReadFile(strings.TrimPrefix(v, "<"))
+				if err != nil {
+					return nil, err
+				}
+				v = string(b)
+			}
+			c.FormValues[name] = append(c.FormValues[name], v)
+		case "--form-string": // literal text field, @ and < are not special
+			name, v, _ := strings.Cut(val, "=")
+			if strings.HasPrefix(v, "@") {
+				v = `\` + v
+			}
+			c.FormValues[name] = append(c.FormValues[name], v)
+		case "-b", "--cookie":
+			if !strings.Contains(val, "=") {
+				c.Warnings = append(c.Warnings, "cookie file is not supported: "+val)
+				continue
+			}
+			cookies = append(cookies, val)
+		case "-u", "--user":
+			user = val
+		case "-A", "--user-agent":
+			c.Headers["User-Agent"] = []string{val}
+		case "-e", "--referer":
+			c.Headers["Referer"] = []string{val}
+		case "--url":
+			rawURL = val
+		case "-x", "--proxy":
+			c.Proxy = val
+		case "--noproxy":
+			if val != "*" {
+				c.Warnings = append(c.Warnings, "no proxy list is not supported: "+val)
+				continue
+			}
+			c.Proxy = proxyDirect
+		case "--cacert", "-E", "--cert", "--key":
+			if c.TLS == nil {
+				c.TLS = &TLS{}
+			}
+			switch opt {
+			case "--cacert":
+				c.TLS.CABundle = val
+			case "--key":
+				c.TLS.ClientKey = val
+			default:
+				c.TLS.ClientCert = val
+			}
+		}
+	}
+
+	if rawURL == "" {
+		return nil, errors.New("URL is not found")
+	}
+	if !strings.Contains(rawURL, "://") {
+		rawURL = "http://" + rawURL
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	c.Scheme = u.Scheme
+	c.Host = u.Host
+	c.UrlPath = u.Path
+	c.RawQuery = u.RawQuery
+
+	if u.User != nil && user == "" {
+		user = u.User.String()
+		if p, ok := u.User.Password(); ok {
+			user = u.User.Username() + ":" + p
+		}
+	}
+	if user != "" {
+		c.Auth = &Auth{Type: authBasic}
+		if digest {
+			c.Auth.Type = authDigest
+		}
+		c.Auth.User, c.Auth.Password, _ = strings.Cut(user, ":")
+	}
+
+	if len(cookies) > 0 {
+		c.Headers["Cookie"] = append(c.Headers["Cookie"], strings.Join(cookies, "; "))
+	}
+
+	body := strings.Join(data, "&")
+	switch {
+	case len(c.FormValues) > 0:
+		c.Payload = multipartPayload
+	case len(data) > 0 && getData:
+		if c.RawQuery != "" {
+			c.RawQuery += "&"
+		}
+		c.RawQuery += body
+	case len(data) > 0:
+		ct := strings.Join(c.Headers["Content-Type"], "")
+		if strings.Contains(ct, "json") || ct == "" && json.Valid([]byte(body)) &&
+			strings.ContainsAny(strings.TrimSpace(body)[:1], "{[") {
+			c.Payload = jsonPayload
+			c.JSON = body
+			break
+		}
+		if ct == "" {
+			ct = "application/x-www-form-urlencoded" // default of curl
+		}
+		if v, ok := parseFormBody(body); ok && strings.HasPrefix(ct, "application/x-www-form-urlencoded") {
+			c.FormValues = v
+			c.Payload = formPayload
+			break
+		}
+		c.Headers["Content-Type"] = []string{ct}
+		c.Raw = body
+		c.Payload = rawPayload
+	}
+
+	switch {
+	case method != "":
+		c.Method = method
+	case head:
+		c.Method = "HEAD"
+	case c.Payload != nothing:
+		c.Method = "POST"
+	default:
+		c.Method = "GET"
+	}
+
+	return c, nil
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSplitShellWords(t *testing.T) {
+	cases := map[string][]string{
+		`curl 'https://example.com/a b' -H "X-Token: \"q\""`: {"curl", "https://example.com/a b", "-H", `X-Token: "q"`},
+		"curl -d 'a=1' \\\n  https://example.com":            {"curl", "-d", "a=1", "https://example.com"},
+		`curl --data-raw $'{"a":\'b\'}\n' x`:                 {"curl", "--data-raw", "{\"a\":'b'}\n", "x"},
+		`curl a\ b`:                                          {"curl", "a b"},
+	}
+	for in, expected := range cases {
+		words, err := splitShellWords(in)
+		if err != nil {
+			t.Errorf("cannot split %q: %s", in, err)
+		}
+		if slices.Compare(words, expected) != 0 {
+			t.Errorf("expected: %q, got: %q", expected, words)
+		}
+	}
+
+	if _, err := splitShellWords(`curl 'unterminated`); err == nil {
+		t.Errorf("expected error of unterminated quote")
+	}
+}
+
+func TestParseCurl(t *testing.T) {
+	t.Run("not a curl", func(t *testing.T) {
+		if _, err := ParseCurl("wget https://example.com"); err == nil {
+			t.Errorf("expected error of not curl command")
+		}
+		if _, err := ParseCurl("curl -sSL"); err == nil {
+			t.Errorf("expected error of missed URL")
+		}
+	})
+
+	t.Run("GET with headers and cookies", func(t *testing.T) {
+		c, err := ParseCurl(`curl -sSL 'https://example.com:8443/api/users?page=2' ` +
+			`-H 'accept: application/json' -b 'a=1; b=2' -u user:pass -k --compressed`)
+		if err != nil {
+			t.Fatalf("cannot parse curl: %s", err)
+		}
+		if c.Method != "GET" || c.Scheme != "https" || c.Host != "example.com:8443" ||
+			c.UrlPath != "/api/users" || c.RawQuery != "page=2" {
+			t.Errorf("unexpected request: %#v", c.Request)
+		}
+		if v := c.Headers["Accept"]; slices.Compare(v, []string{"application/json"}) != 0 {
+			t.Errorf("expected header Accept: application/json, got: %s", v)
+		}
+		if v := c.Headers["Cookie"]; slices.Compare(v, []string{"a=1; b=2"}) != 0 {
+			t.Errorf("expected header Cookie: a=1; b=2, got: %s", v)
+		}
+		if c.Auth == nil || *c.Auth != (Auth{Type: authBasic, User: "user", Password: "pass"}) {
+			t.Errorf("expected Basic auth user:pass, got: %#v", c.Auth)
+		}
+		if !c.Insecure {
+			t.Errorf("expected insecure mode")
+		}
+		if len(c.Warnings) > 0 {
+			t.Errorf("unexpected warnings: %s", c.Warnings)
+		}
+	})
+
+	t.Run("JSON payload", func(t *testing.T) {
+		c, err := ParseCurl(`curl -XPUT example.com/api --data-raw '{"name": "morpheus"}'`)
+		if err != nil {
+			t.Fatalf("cannot parse curl: %s", err)
+		}
+		if c.Method != "PUT" || c.Scheme != "http" || c.Payload != jsonPayload {
+			t.Errorf("expected PUT with JSON payload, got: %s %d", c.Method, c.Payload)
+		}
+		if c.JSON != `{"name": "morpheus"}` {
+			t.Errorf("unexpected JSON payload: %s", c.JSON)
+		}
+	})
+
+	t.Run("form payload", func(t *testing.T) {
+		c, err := ParseCurl(`curl https://example.com/login -d login=neo --data-urlencode 'password=p&ss'`)
+		if err != nil {
+			t.Fatalf("cannot parse curl: %s", err)
+		}
+		if c.Method != "POST" || c.Payload != formPayload {
+			t.Errorf("expected POST with form payload, got: %s %d", c.Method, c.Payload)
+		}
+		if c.FormValues["login"][0] != "neo" || c.FormValues["password"][0] != "p&ss" {
+			t.Errorf("unexpected form values: %s", c.FormValues)
+		}
+	})
+
+	t.Run("multipart form", func(t *testing.T) {
+		c, err := ParseCurl(`curl https://example.com/upload -F name=neo -F 'avatar=@/tmp/a.png;type=image/png'`)
+		if err != nil {
+			t.Fatalf("cannot parse curl: %s", err)
+		}
+		if c.Method != "POST" || c.Payload != multipartPayload {
+			t.Errorf("expected POST with multipart payload, got: %s %d", c.Method, c.Payload)
+		}
+		if c.FormValues["name"][0] != "neo" || c.FormValues["avatar"][0] != "@/tmp/a.png;type=image/png" {
+			t.Errorf("unexpected form values: %s", c.FormValues)
+		}
+	})
+
+	t.Run("form string", func(t *testing.T) {
+		c, err := ParseCurl(`curl https://example.com/upload --form-string 'nick=@neo' --form-string note='<b>'`)
+		if err != nil {
+			t.Fatalf("cannot parse curl: %s", err)
+		}
+		if c.Payload != multipartPayload {
+			t.Errorf("expected multipart payload, got: %d", c.Payload)
+		}
+		if hasFileParts(c.FormValues) || c.FormValues["note"][0] != "<b>" {
+			t.Errorf("expected literal text fields, got: %s", c.FormValues)
+		}
+		if p := ParseFormPart("nick", c.FormValues["nick"][0]); p.File != "" || p.Value != "@neo" {
+			t.Errorf("expected text field @neo, got: %#v", p)
+		}
+	})
+
+	t.Run("raw payload", func(t *testing.T) {
+		for cmd, expected := range map[string]string{
+			`curl https://example.com -d foo`:                                              "application/x-www-form-urlencoded foo",
+			`curl https://example.com -d 'a=1;b=2'`:                                        "application/x-www-form-urlencoded a=1;b=2",
+			`curl https://example.com -H 'Content-Type: application/xml' -d '<a>x=1</a>'`:  "application/xml <a>x=1</a>",
+			`curl https://example.com -H 'Content-Type: application/xml' --data-raw 'a=b'`: "application/xml a=b",
+		} {
+			c, err := ParseCurl(cmd)
+			if err != nil {
+				t.Errorf("cannot parse curl %s: %s", cmd, err)
+				continue
+			}
+			if c.Payload != rawPayload || c.Method != "POST" {
+				t.Errorf("expected POST with raw payload of %s, got: %s %d", cmd, c.Method, c.Payload)
+			}
+			if v := c.Headers["Content-Type"][0] + " " + c.Raw; v != expected {
+				t.Errorf("expected %q of %s, got: %q", expected, cmd, v)
+			}
+		}
+	})
+
+	t.Run("inline empty value", func(t *testing.T) {
+		c, err := ParseCurl(`curl --data= https://example.com/`)
+		if err != nil {
+			t.Fatalf("cannot parse curl: %s", err)
+		}
+		if c.Host != "example.com" || c.Payload != rawPayload || c.Raw != "" {
+			t.Errorf("expected URL is not consumed by empty data, got: %s %d %q", c.Host, c.Payload, c.Raw)
+		}
+	})
+
+	t.Run("data to query string", func(t *testing.T) {
+		c, _ := ParseCurl(`curl -G https://example.com/search?q=1 -d limit=10 --unknown-flag`)
+		if c.Method != "GET" || c.RawQuery != "q=1&limit=10" {
+			t.Errorf("expected GET with query q=1&limit=10, got: %s %s", c.Method, c.RawQuery)
+		}
+		if len(c.Warnings) != 1 {
+			t.Errorf("expected warning about unsupported option, got: %s", c.Warnings)
+		}
+	})
+}
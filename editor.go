@@ -0,0 +1,151 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/charmbracelet/bubbles/textarea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Rune of body editor and index of its style.
+type editorCell struct {
+	r     rune
+	style int
+}
+
+// Chroma style of theme.
+func themeChromaStyle() *chroma.Style {
+	if style := styles.Get(chromaStyle); style != nil {
+		return style
+	}
+	return styles.Fallback
+}
+
+// Split text highlighted by lexer to lines of runes with their styles.
+func highlightCells(lexer chroma.Lexer, s string) ([][]editorCell, []lipgloss.Style) {
+	var (
+		theme  = themeChromaStyle()
+		lines  = [][]editorCell{nil}
+		styles []lipgloss.Style
+		index  = make(map[chroma.TokenType]int)
+	)
+	it, err := lexer.Tokenise(nil, s)
+	if err != nil { // show it as plain text
+		it = chroma.Literator(chroma.Token{Type: chroma.Text, Value: s})
+	}
+	for tok := it(); tok != chroma.EOF; tok = it() {
+		i, ok := index[tok.Type]
+		if !ok {
+			e := theme.Get(tok.Type)
+			st := lipgloss.NewStyle().Bold(e.Bold == chroma.Yes).Italic(e.Italic == chroma.Yes).
+				Underline(e.Underline == chroma.Yes)
+			if e.Colour.IsSet() {
+				st = st.Foreground(lipgloss.Color(e.Colour.String()))
+			}
+			i = len(styles)
+			index[tok.Type] = i
+			styles = append(styles, st)
+		}
+		for _, r := range tok.Value {
+			if r == '\n' {
+				lines = append(lines, nil)
+				continue
+			}
+			lines[len(lines)-1] = append(lines[len(lines)-1], editorCell{r, i})
+		}
+	}
+	return lines, styles
+}
+
+// Line and column of cursor of textarea.
+func editorCursor(ta textarea.Model) (int, int) {
+	li := ta.LineInfo()
+	return ta.Line(), li.StartColumn + li.ColumnOffset
+}
+
+// Index of wrapped line of cursor: lines of editor are wrapped by width of textarea.
+func editorCursorRow(ta textarea.Model) int {
+	w := max(ta.Width(), 1)
+	row, col := editorCursor(ta)
+	n := 0
+	for i, line := range strings.Split(ta.Value(), "\n") {
+		if i == row {
+			return n + col/w
+		}
+		n += max((utf8.RuneCountInString(line)+w-1)/w, 1)
+	}
+	return n
+}
+
+// First visible line of editor: the view is scrolled to keep the cursor visible.
+func editorScroll(ta textarea.Model, top int) int {
+	cursor := editorCursorRow(ta)
+	return max(min(top, cursor), cursor-max(ta.Height(), 1)+1, 0)
+}
+
+// Render cells, cur is the index of cursor or -1.
+func renderCells(cells []editorCell, styles []lipgloss.Style, cur int) string {
+	var b strings.Builder
+	for start := 0; start < len(cells); {
+		if start == cur {
+			b.WriteString(styles[cells[start].style].Reverse(true).Render(string(cells[start].r)))
+			start++
+			continue
+		}
+		end := start + 1
+		for end < len(cells) && end != cur && cells[end].style == cells[start].style {
+			end++
+		}
+		var s strings.Builder
+		for _, c := range cells[start:end] {
+			s.WriteRune(c.r)
+		}
+		b.WriteString(styles[cells[start].style].Render(s.String()))
+		start = end
+	}
+	if cur == len(cells) {
+		b.WriteString(lipgloss.NewStyle().Reverse(true).Render(" "))
+	}
+	return b.String()
+}
+
+// View of body editor: the text of textarea is highlighted by lexer of content type,
+// wrapped by width of textarea and scrolled from the top line to the cursor.
+func editorView(ta textarea.Model, ct string, top int) string {
+	if ta.Value() == "" {
+		return ta.View() // placeholder
+	}
+	lines, styles := highlightCells(lexerOf(ct, ta.Value()), ta.Value())
+	lines = lines[:min(len(lines), ta.LineCount())] // lexers may add the final newline
+
+	style := ta.BlurredStyle
+	if ta.Focused() {
+		style = ta.FocusedStyle
+	}
+	w := max(ta.Width(), 1)
+	row, col := editorCursor(ta)
+	var rows []string
+	for i, line := range lines {
+		for start := 0; start == 0 || start < len(line) || i == row && start <= col; start += w {
+			prefix := style.Prompt.Render(ta.Prompt)
+			if ta.ShowLineNumbers {
+				n := ""
+				if start == 0 {
+					n = fmt.Sprint(i + 1)
+				}
+				prefix += style.LineNumber.Render(fmt.Sprintf("%3v ", n))
+			}
+			cur := -1
+			if ta.Focused() && i == row && col >= start && col < start+w {
+				cur = col - start
+			}
+			rows = append(rows, prefix+renderCells(line[start:min(start+w, len(line))], styles, cur))
+		}
+	}
+	top = editorScroll(ta, top)
+	return strings.Join(rows[min(top, len(rows)):min(top+max(ta.Height(), 1), len(rows))], "\n")
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// Environment is a named set of variables, their values are substituted
+// instead of {{name}} placeholders of request fields at send time.
+type Environment map[string]string
+
+var (
+	environments map[string]Environment
+	activeEnv    string // name of active environment, empty means no environment
+
+	envVarRegexp = regexp.MustCompile(`{{\s*([\w.-]+)\s*}}`)
+)
+
+// Set available environments and the active one.
+func setEnvironments(envs map[string]Environment, active string) bool {
+	environments = envs
+	if _, ok := environments[active]; !ok {
+		activeEnv = ""
+		return active == ""
+	}
+	activeEnv = active
+	return true
+}
+
+// Names of environments in alphabetical order.
+func environmentNames() []string {
+	var names []string
+	for name := range environments {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Switch to next environment, after the last one environments are turned off.
+func nextEnvironment() string {
+	names := environmentNames()
+	idx := slices.Index(names, activeEnv)
+	if idx+1 < len(names) {
+		activeEnv = names[idx+1]
+	} else {
+		activeEnv = ""
+	}
+	return activeEnv
+}
+
+// Expand {{name}} placeholders by variables of active environment,
+// unknown variables are left as is.
+func expandVars(s string) string {
+	env, ok := environments[activeEnv]
+	if !ok || !strings.Contains(s, "{{") {
+		return s
+	}
+	return envVarRegexp.ReplaceAllStringFunc(s, func(v string) string {
+		name := envVarRegexp.FindStringSubmatch(v)[1]
+		if val, ok := env[name]; ok {
+			return val
+		}
+		return v
+	})
+}
+
+// Expand placeholders of all values.
+func expandValues(v map[string][]string) map[string][]string {
+	res := make(map[string][]string, len(v))
+	for k, vals := range v {
+		var expanded []string
+		for _, val := range vals {
+			expanded = append(expanded, expandVars(val))
+		}
+		res[expandVars(k)] = expanded
+	}
+	return res
+}
+
+// Create a copy of request with expanded placeholders of method, host, path,
+// headers (cookies as well) and query params, the original request is kept untouched.
+func expandRequest(r *http.Request) *http.Request {
+	rc := r.Clone(r.Context())
+	if _, ok := environments[activeEnv]; !ok {
+		return rc
+	}
+	rc.Method = expandVars(r.Method)
+	rc.Host = expandVars(r.Host)
+	rc.URL.Host = expandVars(r.URL.Host)
+	rc.URL.Path = expandVars(r.URL.Path)
+	rc.Header = expandValues(r.Header)
+	if q, err := url.ParseQuery(r.URL.RawQuery); err == nil {
+		rc.URL.RawQuery = url.Values(expandValues(q)).Encode()
+	}
+	return rc
+}
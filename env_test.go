@@ -0,0 +1,101 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"testing"
+)
+
+func TestEnvironment(t *testing.T) {
+	envs := map[string]Environment{
+		"local": {"host": "localhost:8080", "token": "secret"},
+		"prod":  {"host": "example.com", "token": "prod-secret"},
+	}
+
+	t.Run("unknown environment", func(t *testing.T) {
+		if setEnvironments(envs, "stage") {
+			t.Errorf("expected unknown environment to be rejected")
+		}
+		if activeEnv != "" {
+			t.Errorf("expected no active environment, got: %s", activeEnv)
+		}
+		if s := expandVars("{{host}}"); s != "{{host}}" {
+			t.Errorf("expected placeholder is left as is, got: %s", s)
+		}
+	})
+
+	t.Run("switch environments", func(t *testing.T) {
+		setEnvironments(envs, "")
+		for _, expected := range []string{"local", "prod", "", "local"} {
+			if name := nextEnvironment(); name != expected {
+				t.Errorf("expected environment: %q, got: %q", expected, name)
+			}
+		}
+	})
+
+	t.Run("expand vars", func(t *testing.T) {
+		setEnvironments(envs, "prod")
+		cases := map[string]string{
+			"https://{{host}}/api":      "https://example.com/api",
+			"Bearer {{ token }}":        "Bearer prod-secret",
+			"{{unknown}} and {{token}}": "{{unknown}} and prod-secret",
+			`{"host": "{{host}}"}`:      `{"host": "example.com"}`,
+			"no placeholders":           "no placeholders",
+		}
+		for in, expected := range cases {
+			if s := expandVars(in); s != expected {
+				t.Errorf("expected: %s, got: %s", expected, s)
+			}
+		}
+	})
+
+	t.Run("expand request", func(t *testing.T) {
+		setEnvironments(envs, "local")
+		r, _ := http.NewRequest("GET", "http://localhost/{{token}}?t={{token}}", nil)
+		r.Host = "{{host}}"
+		r.URL.Host = "{{host}}"
+		r.Header.Set("Authorization", "Bearer {{token}}")
+
+		rc := expandRequest(r)
+		if rc.URL.String() != "http://localhost:8080/secret?t=secret" {
+			t.Errorf("expected url: http://localhost:8080/secret?t=secret, got: %s", rc.URL)
+		}
+		if v := rc.Header.Get("Authorization"); v != "Bearer secret" {
+			t.Errorf("expected header Authorization: Bearer secret, got: %s", v)
+		}
+		if r.Header.Get("Authorization") != "Bearer {{token}}" || r.URL.Host != "{{host}}" {
+			t.Errorf("original request must be untouched, got: %s %s", r.URL, r.Header)
+		}
+	})
+
+	t.Run("expand payload", func(t *testing.T) {
+		setEnvironments(envs, "local")
+		formValues = map[string][]string{"token": {"{{token}}"}}
+		r, _ := http.NewRequest("POST", "http://localhost", nil)
+		prepareRequest(r, newSendState(r, formPayload))
+		b, _ := io.ReadAll(r.Body)
+		if string(b) != "token=secret" {
+			t.Errorf("expected form payload: token=secret, got: %s", b)
+		}
+		formValues = make(map[string][]string)
+	})
+
+	t.Run("send state", func(t *testing.T) {
+		setEnvironments(envs, "local")
+		formValues = map[string][]string{"token": {"{{token}}"}}
+		reqAuth = Auth{Type: authBearer, Token: "{{token}}"}
+		r, _ := http.NewRequest("POST", "http://localhost/", nil)
+		r.URL.Host = "{{host}}"
+		s := newSendState(r, formPayload)
+
+		// edits of UI after the request is sent
+		setEnvironments(envs, "prod")
+		formValues["token"][0], reqAuth.Token = "changed", "changed"
+
+		if s.form["token"][0] != "secret" || s.auth.Token != "secret" || s.req.URL.Host != "localhost:8080" {
+			t.Errorf("expected state of local environment, got: %v %s %s", s.form, s.auth.Token, s.req.URL)
+		}
+		formValues, reqAuth = make(map[string][]string), Auth{}
+	})
+	setEnvironments(nil, "")
+}
@@ -0,0 +1,247 @@
+package main
+
+import (
+	"mime"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Export formats.
+const (
+	exportCurl = iota
+	exportHTTPie
+	exportGo
+
+	exportFormatsEnd
+)
+
+var exportFormatNames = [exportFormatsEnd]string{"curl", "HTTPie", "Go"}
+
+// Quote string for the shell.
+func shellQuote(s string) string {
+	if s != "" && !strings.ContainsFunc(s, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' ||
+			strings.ContainsRune("-_./:@,+=%", r))
+	}) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// Header lines of request in alphabetical order, the Host header is skipped.
+func exportHeaders(r *http.Request) [][2]string {
+	var names []string
+	for name := range r.Header {
+		if name != "Host" { // the host of URL is used by clients
+			names = append(names, name)
+		}
+	}
+	slices.Sort(names)
+
+	var headers [][2]string
+	for _, name := range names {
+		for _, v := range r.Header[name] {
+			headers = append(headers, [2]string{name, v})
+		}
+	}
+	return headers
+}
+
+// Body of request payload with expanded placeholders.
+func exportBody(p int) string {
+	switch p {
+	case formPayload:
+		return url.Values(expandValues(formValues)).Encode()
+	case jsonPayload:
+		return expandVars(jsonPayloadEncoded)
+	case rawPayload:
+		return expandVars(rawPayloadEncoded)
+	}
+	return ""
+}
+
+// Render request as curl command.
+func exportAsCurl(r *http.Request, p int) string {
+	args := []string{"curl", "-X", r.Method, shellQuote(r.URL.String())}
+	switch proxy := expandVars(reqProxy); proxy {
+	case "":
+	case proxyDirect:
+		args = append(args, "--noproxy", shellQuote("*"))
+	default:
+		args = append(args, "--proxy", shellQuote(proxy))
+	}
+	switch reqAuth.Type {
+	case authDigest:
+		args = append(args, "--digest", "-u", shellQuote(expandVars(reqAuth.User)+":"+expandVars(reqAuth.Password)))
+	case authSigV4:
+		args = append(args, "--aws-sigv4", shellQuote("aws:amz:"+expandVars(reqAuth.Region)+":"+expandVars(reqAuth.Service)),
+			"-u", shellQuote(expandVars(reqAuth.User)+":"+expandVars(reqAuth.Password)))
+		if token := expandVars(reqAuth.Token); token != "" {
+			args = append(args, "-H", shellQuote("X-Amz-Security-Token: "+token))
+		}
+	}
+	for _, h := range exportHeaders(r) {
+		args = append(args, "-H", shellQuote(h[0]+": "+h[1]))
+	}
+	switch p {
+	case formPayload, jsonPayload, rawPayload:
+		args = append(args, "--data-raw", shellQuote(exportBody(p)))
+	case file:
+		args = append(args, "--data-binary", shellQuote("@"+filePayload))
+	case multipartPayload:
+		for _, part := range formParts(expandValues(formValues)) {
+			opt := "-F"
+			if part.File == "" && (strings.HasPrefix(part.Value, "@") || strings.HasPrefix(part.Value, "<")) {
+				opt = "--form-string" // literal text, not a file
+			}
+			args = append(args, opt, shellQuote(part.Name+"="+part.Value))
+		}
+	}
+	return strings.Join(args, " \\\n  ") + "\n"
+}
+
+// Render request as HTTPie command.
+func exportAsHTTPie(r *http.Request, p int) string {
+	args := []string{"http"}
+	switch p {
+	case formPayload:
+		args = append(args, "--form")
+	case multipartPayload:
+		args = append(args, "--multipart")
+	}
+	if reqAuth.Type == authDigest {
+		args = append(args, "-A", "digest", "-a", shellQuote(expandVars(reqAuth.User)+":"+expandVars(reqAuth.Password)))
+	}
+	args = append(args, r.Method, shellQuote(r.URL.String()))
+	for _, h := range exportHeaders(r) {
+		if (p == formPayload || p == multipartPayload) && h[0] == "Content-Type" {
+			continue // set by --form or --multipart
+		}
+		args = append(args, shellQuote(h[0]+":"+h[1]))
+	}
+	switch p {
+	case formPayload:
+		values := expandValues(formValues)
+		var keys []string
+		for k := range values {
+			keys = append(keys, k)
+		}
+		slices.Sort(keys)
+		for _, k := range keys {
+			for _, v := range values[k] {
+				args = append(args, shellQuote(k+"="+v))
+			}
+		}
+	case multipartPayload:
+		for _, part := range formParts(expandValues(formValues)) {
+			if part.File != "" {
+				args = append(args, shellQuote(part.Name+"@"+part.File+";type="+part.ContentType))
+			} else {
+				args = append(args, shellQuote(part.Name+"="+part.Value))
+			}
+		}
+	case jsonPayload, rawPayload:
+		args = append(args, "--raw", shellQuote(exportBody(p)))
+	case file:
+		args = append(args, "@"+shellQuote(filePayload))
+	}
+	return strings.Join(args, " \\\n  ") + "\n"
+}
+
+// Render request as Go code.
+func exportAsGo(r *http.Request, p int) string {
+	var b strings.Builder
+	q := strconv.Quote
+
+	b.WriteString("package main\n\nimport (\n\t\"fmt\"\n\t\"io\"\n\t\"net/http\"\n")
+	switch p {
+	case formPayload, jsonPayload, rawPayload:
+		b.WriteString("\t\"strings\"\n")
+	case file:
+		b.WriteString("\t\"os\"\n")
+	case multipartPayload:
+		b.WriteString("\t\"bytes\"\n\t\"mime/multipart\"\n")
+		if hasFileParts(formValues) {
+			b.WriteString("\t\"os\"\n")
+		}
+	}
+	b.WriteString(")\n\nfunc main() {\n")
+
+	body := "nil"
+	switch p {
+	case formPayload, jsonPayload, rawPayload:
+		b.WriteString("\tbody := strings.NewReader(" + q(exportBody(p)) + ")\n")
+		body = "body"
+	case file:
+		b.WriteString("\tbody, err := os.Open(" + q(filePayload) + ")\n")
+		b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n\tdefer body.Close()\n\n")
+		body = "body"
+	case multipartPayload:
+		b.WriteString("\tbody := new(bytes.Buffer)\n\tw := multipart.NewWriter(body)\n")
+		for _, part := range formParts(expandValues(formValues)) {
+			if part.File == "" {
+				b.WriteString("\tw.WriteField(" + q(part.Name) + ", " + q(part.Value) + ")\n")
+				continue
+			}
+			b.WriteString("\tif b, err := os.ReadFile(" + q(part.File) + "); err != nil {\n")
+			b.WriteString("\t\tpanic(err)\n\t} else {\n")
+			disposition := mime.FormatMediaType("form-data",
+				map[string]string{"name": part.Name, "filename": filepath.Base(part.File)})
+			b.WriteString("\t\tpw, _ := w.CreatePart(map[string][]string{\n")
+			b.WriteString("\t\t\t\"Content-Disposition\": {" + q(disposition) + "},\n")
+			b.WriteString("\t\t\t\"Content-Type\":        {" + q(part.ContentType) + "},\n\t\t})\n")
+			b.WriteString("\t\tpw.Write(b)\n\t}\n")
+		}
+		b.WriteString("\tw.Close()\n\n")
+		body = "body"
+	}
+
+	b.WriteString("\treq, err := http.NewRequest(" + q(r.Method) + ", " + q(r.URL.String()) + ", " + body + ")\n")
+	b.WriteString("\tif err != nil {\n\t\tpanic(err)\n\t}\n")
+	for _, h := range exportHeaders(r) {
+		if p == multipartPayload && h[0] == "Content-Type" {
+			b.WriteString("\treq.Header.Set(\"Content-Type\", w.FormDataContentType())\n")
+			continue
+		}
+		b.WriteString("\treq.Header.Add(" + q(h[0]) + ", " + q(h[1]) + ")\n")
+	}
+	if reqAuth.Type == authDigest {
+		b.WriteString("\t// TODO Digest auth: net/http has no support of it, see RFC 7616\n")
+	}
+	b.WriteString(`
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		panic(err)
+	}
+	defer res.Body.Close()
+
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Println(res.Status)
+	fmt.Println(string(b))
+}
+`)
+	return b.String()
+}
+
+// Export request in the given format, placeholders of active environment are expanded.
+func exportRequest(r *http.Request, p, format int) string {
+	r = expandRequest(r)
+	applyAuth(r, expandAuth(reqAuth))
+	if t := cachedOAuth2Token(reqAuth); reqAuth.Type == authOAuth2 && t != nil {
+		r.Header.Set("Authorization", "Bearer "+t.AccessToken)
+	}
+	switch format {
+	case exportHTTPie:
+		return exportAsHTTPie(r, p)
+	case exportGo:
+		return exportAsGo(r, p)
+	}
+	return exportAsCurl(r, p)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExportAsCurl(t *testing.T) {
+	r, _ := http.NewRequest("PUT", "https://example.com/api/users?id=2", nil)
+	r.Header.Set("X-Token", "it's secret")
+	r.Header.Set("Content-Type", "application/json")
+	r.Header.Set("Host", "example.org")
+	jsonPayloadEncoded = `{"name":"neo"}`
+	defer func() { jsonPayloadEncoded = "" }()
+
+	c, err := ParseCurl(exportRequest(r, jsonPayload, exportCurl))
+	if err != nil {
+		t.Fatalf("cannot parse exported curl command: %s", err)
+	}
+	if c.Method != "PUT" || c.Host != "example.com" || c.RawQuery != "id=2" || c.JSON != jsonPayloadEncoded {
+		t.Errorf("unexpected request: %#v", c)
+	}
+	if v := c.Headers["X-Token"]; slices.Compare(v, []string{"it's secret"}) != 0 {
+		t.Errorf("expected header X-Token: it's secret, got: %s", v)
+	}
+	if v, ok := c.Headers["Host"]; ok {
+		t.Errorf("expected Host header is skipped, got: %s", v)
+	}
+
+	t.Run("proxy", func(t *testing.T) {
+		defer func() { reqProxy = "" }()
+		for proxy, expected := range map[string]string{
+			"socks5://127.0.0.1:1080": "--proxy \\\n  socks5://127.0.0.1:1080",
+			proxyDirect:               "--noproxy \\\n  '*'",
+		} {
+			reqProxy = proxy
+			cmd := exportRequest(r, nothing, exportCurl)
+			if !strings.Contains(cmd, expected) {
+				t.Errorf("expected %s, got: %s", expected, cmd)
+			}
+			if c, err := ParseCurl(cmd); err != nil || c.Proxy != proxy {
+				t.Errorf("expected proxy %s of imported command, got: %v %#v", proxy, err, c)
+			}
+		}
+	})
+}
+
+func TestExportAsHTTPie(t *testing.T) {
+	r, _ := http.NewRequest("POST", "https://example.com/login", nil)
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("X-Token", "it's secret")
+	formValues = url.Values{"user": {"neo"}, "pass": {"a b"}}
+	defer func() { formValues = make(url.Values) }()
+
+	expected := "http \\\n  --form \\\n  POST \\\n  https://example.com/login \\\n  'X-Token:it'\\''s secret' \\\n" +
+		"  'pass=a b' \\\n  user=neo\n"
+	if out := exportRequest(r, formPayload, exportHTTPie); out != expected {
+		t.Errorf("expected:\n%s\ngot:\n%s", expected, out)
+	}
+}
+
+func TestExportAsGo(t *testing.T) {
+	r, _ := http.NewRequest("PATCH", "https://example.com/api/users/2", nil)
+	r.Header.Set("Content-Type", "application/json")
+	jsonPayloadEncoded = `{"name":"neo"}`
+	defer func() { jsonPayloadEncoded = "" }()
+
+	out := exportRequest(r, jsonPayload, exportGo)
+	if _, err := parser.ParseFile(token.NewFileSet(), "main.go", out, 0); err != nil {
+		t.Errorf("expected valid Go code, got: %s\n%s", err, out)
+	}
+	for _, s := range []string{
+		"\t\"strings\"\n",
+		"\tbody := strings.NewReader(\"{\\\"name\\\":\\\"neo\\\"}\")\n",
+		"\treq, err := http.NewRequest(\"PATCH\", \"https://example.com/api/users/2\", body)\n",
+		"\treq.Header.Add(\"Content-Type\", \"application/json\")\n",
+	} {
+		if !strings.Contains(out, s) {
+			t.Errorf("expected %q in Go code, got:\n%s", s, out)
+		}
+	}
+}
+
+func TestExportExpandsBody(t *testing.T) {
+	envs, env := environments, activeEnv
+	defer func() { environments, activeEnv = envs, env }()
+	setEnvironments(map[string]Environment{"dev": {"name": "neo", "file": "/tmp/a.png"}}, "dev")
+
+	r, _ := http.NewRequest("POST", "https://example.com/upload", nil)
+	formValues = url.Values{"user": {"{{name}}"}, "avatar": {"@{{file}};type=image/png"}}
+	rawPayloadEncoded = "<user>{{name}}</user>"
+	defer func() { formValues, rawPayloadEncoded = make(url.Values), "" }()
+
+	for _, tc := range []struct {
+		p, format int
+		expected  []string
+	}{
+		{formPayload, exportCurl, []string{"'avatar=%40%2Ftmp%2Fa.png%3Btype%3Dimage%2Fpng&user=neo'"}},
+		{formPayload, exportHTTPie, []string{"user=neo"}},
+		{rawPayload, exportCurl, []string{"'<user>neo</user>'"}},
+		{rawPayload, exportGo, []string{`strings.NewReader("<user>neo</user>")`}},
+		{multipartPayload, exportCurl, []string{"-F \\\n  'avatar=@/tmp/a.png;type=image/png'", "-F \\\n  user=neo"}},
+		{multipartPayload, exportHTTPie, []string{"'avatar@/tmp/a.png;type=image/png'", "user=neo"}},
+		{multipartPayload, exportGo, []string{
+			`w.WriteField("user", "neo")`,
+			`os.ReadFile("/tmp/a.png")`,
+			`"Content-Disposition": {"form-data; filename=a.png; name=avatar"},`,
+			`"Content-Type":        {"image/png"},`,
+		}},
+	} {
+		out := exportRequest(r, tc.p, tc.format)
+		if strings.Contains(out, "{{") {
+			t.Errorf("expected expanded placeholders in %s export, got:\n%s", exportFormatNames[tc.format], out)
+		}
+		for _, s := range tc.expected {
+			if !strings.Contains(out, s) {
+				t.Errorf("expected %q in %s export, got:\n%s", s, exportFormatNames[tc.format], out)
+			}
+		}
+		if tc.format == exportGo {
+			if _, err := parser.ParseFile(token.NewFileSet(), "main.go", out, 0); err != nil {
+				t.Errorf("expected valid Go code, got: %s\n%s", err, out)
+			}
+		}
+	}
+}
@@ -0,0 +1,314 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Steps of path of filter.
+const (
+	stepKey   = iota // .name, ["name"]
+	stepIndex        // [0], [-1]
+	stepIter         // [] or [*]: elements of array, values of object
+	stepSlice        // [1:3], [:2], [-2:]
+)
+
+type filterStep struct {
+	kind     int
+	key      string
+	from, to *int // index (from) or bounds of slice, nil means the start or the end
+}
+
+// Stage of filter: a path or a function (keys, length).
+type filterStage struct {
+	fn    string
+	steps []filterStep
+}
+
+// Functions of filter.
+var filterFuncs = []string{"keys", "length"}
+
+// Parse filter: stages separated by pipe, every stage is a jq-like path (.a.b[0], .items[].id)
+// or JSONPath ($.a.b[0], $.items[*].id) or one of functions: keys, length.
+func parseFilter(expr string) ([]filterStage, error) {
+	var stages []filterStage
+	for _, s := range strings.Split(expr, "|") {
+		s = strings.TrimSpace(s)
+		if slices.Contains(filterFuncs, s) {
+			stages = append(stages, filterStage{fn: s})
+			continue
+		}
+		steps, err := parseFilterPath(s)
+		if err != nil {
+			return nil, err
+		}
+		stages = append(stages, filterStage{steps: steps})
+	}
+	return stages, nil
+}
+
+func parseFilterPath(s string) ([]filterStep, error) {
+	path := s
+	if p, ok := strings.CutPrefix(s, "$"); ok {
+		path = p
+	} else if !strings.HasPrefix(s, ".") {
+		return nil, errors.New("filter should start with . or $: " + s)
+	}
+
+	var steps []filterStep
+	for i := 0; i < len(path); {
+		switch path[i] {
+		case '.':
+			i++
+			if i == len(path) || path[i] == '[' {
+				continue // identity or .[0]
+			}
+			if path[i] == '"' {
+				end := strings.IndexByte(path[i+1:], '"')
+				if end < 0 {
+					return nil, errors.New("unterminated quote: " + s)
+				}
+				steps = append(steps, filterStep{kind: stepKey, key: path[i+1 : i+1+end]})
+				i += end + 2
+				continue
+			}
+			end := strings.IndexAny(path[i:], ".[")
+			if end < 0 {
+				end = len(path) - i
+			}
+			if end == 0 {
+				return nil, errors.New("empty key: " + s)
+			}
+			steps = append(steps, filterStep{kind: stepKey, key: path[i : i+end]})
+			i += end
+		case '[':
+			end := strings.IndexByte(path[i:], ']')
+			if end < 0 {
+				return nil, errors.New("unterminated bracket: " + s)
+			}
+			step, err := parseFilterBracket(strings.TrimSpace(path[i+1 : i+end]))
+			if err != nil {
+				return nil, err
+			}
+			steps = append(steps, step)
+			i += end + 1
+		default:
+			return nil, errors.New("unexpected " + strconv.QuoteRune(rune(path[i])) + " of filter: " + s)
+		}
+	}
+	return steps, nil
+}
+
+// Parse content of brackets: empty or *, index, slice or quoted key.
+func parseFilterBracket(b string) (filterStep, error) {
+	switch {
+	case b == "" || b == "*":
+		return filterStep{kind: stepIter}, nil
+	case len(b) > 1 && (b[0] == '"' || b[0] == '\'') && b[len(b)-1] == b[0]:
+		return filterStep{kind: stepKey, key: b[1 : len(b)-1]}, nil
+	case strings.Contains(b, ":"):
+		step := filterStep{kind: stepSlice}
+		from, to, _ := strings.Cut(b, ":")
+		for _, v := range []struct {
+			s   string
+			dst **int
+		}{{from, &step.from}, {to, &step.to}} {
+			if v.s = strings.TrimSpace(v.s); v.s == "" {
+				continue
+			}
+			n, err := strconv.Atoi(v.s)
+			if err != nil {
+				return step, errors.New("invalid slice: [" + b + "]")
+			}
+			*v.dst = &n
+		}
+		return step, nil
+	}
+	n, err := strconv.Atoi(b)
+	if err != nil {
+		return filterStep{}, errors.New("invalid index: [" + b + "]")
+	}
+	return filterStep{kind: stepIndex, from: &n}, nil
+}
+
+// Name of JSON type of value.
+func jsonTypeName(v any) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	}
+	return "object"
+}
+
+// Keys of object in alphabetical order.
+func sortedKeys(o map[string]any) []string {
+	var keys []string
+	for k := range o {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// Bound of slice or index: negative ones are counted from the end, the result is in [0, n].
+func sliceBound(i *int, def, n int) int {
+	if i == nil {
+		return def
+	}
+	v := *i
+	if v < 0 {
+		v += n
+	}
+	return min(max(v, 0), n)
+}
+
+// Apply step to value, null is passed through as jq does.
+func (s filterStep) apply(v any) ([]any, error) {
+	if v == nil {
+		return []any{nil}, nil
+	}
+	switch s.kind {
+	case stepKey:
+		if o, ok := v.(map[string]any); ok {
+			return []any{o[s.key]}, nil
+		}
+		return nil, errors.New("cannot get key " + strconv.Quote(s.key) + " of " + jsonTypeName(v))
+	case stepIter:
+		switch t := v.(type) {
+		case []any:
+			return t, nil
+		case map[string]any:
+			var vals []any
+			for _, k := range sortedKeys(t) {
+				vals = append(vals, t[k])
+			}
+			return vals, nil
+		}
+		return nil, errors.New("cannot iterate over " + jsonTypeName(v))
+	}
+
+	a, ok := v.([]any)
+	if !ok {
+		return nil, errors.New("cannot index " + jsonTypeName(v) + " with number")
+	}
+	if s.kind == stepIndex {
+		i := *s.from
+		if i < 0 {
+			i += len(a)
+		}
+		if i < 0 || i >= len(a) {
+			return []any{nil}, nil
+		}
+		return []any{a[i]}, nil
+	}
+	from, to := sliceBound(s.from, 0, len(a)), sliceBound(s.to, len(a), len(a))
+	if from > to {
+		from = to
+	}
+	return []any{a[from:to]}, nil
+}
+
+// Apply function to value.
+func applyFilterFunc(fn string, v any) (any, error) {
+	switch t := v.(type) {
+	case map[string]any:
+		if fn == "length" {
+			return len(t), nil
+		}
+		var keys []any
+		for _, k := range sortedKeys(t) {
+			keys = append(keys, k)
+		}
+		return keys, nil
+	case []any:
+		if fn == "length" {
+			return len(t), nil
+		}
+		keys := make([]any, len(t))
+		for i := range t {
+			keys[i] = i
+		}
+		return keys, nil
+	case string:
+		if fn == "length" {
+			return len([]rune(t)), nil
+		}
+	case nil:
+		if fn == "length" {
+			return 0, nil
+		}
+	}
+	return nil, errors.New(jsonTypeName(v) + " has no " + fn)
+}
+
+// Apply filter to value, the result is a stream of values.
+func applyFilter(stages []filterStage, v any) ([]any, error) {
+	vals := []any{v}
+	for _, st := range stages {
+		if st.fn != "" {
+			for i := range vals {
+				var err error
+				if vals[i], err = applyFilterFunc(st.fn, vals[i]); err != nil {
+					return nil, err
+				}
+			}
+			continue
+		}
+		for _, step := range st.steps {
+			var next []any
+			for _, val := range vals {
+				res, err := step.apply(val)
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, res...)
+			}
+			vals = next
+		}
+	}
+	return vals, nil
+}
+
+// Filter JSON body, the result values are separated by new line, they are indented
+// by tabs if indent is set.
+func filterJSON(expr, body string, indent bool) (string, error) {
+	stages, err := parseFilter(expr)
+	if err != nil {
+		return "", err
+	}
+	d := json.NewDecoder(strings.NewReader(body))
+	d.UseNumber()
+	var v any
+	if err := d.Decode(&v); err != nil {
+		return "", errors.New("response body is not JSON: " + err.Error())
+	}
+	vals, err := applyFilter(stages, v)
+	if err != nil {
+		return "", err
+	}
+
+	var out bytes.Buffer
+	e := json.NewEncoder(&out)
+	e.SetEscapeHTML(false)
+	if indent {
+		e.SetIndent("", "\t")
+	}
+	for _, val := range vals {
+		if err := e.Encode(val); err != nil {
+			return "", err
+		}
+	}
+	return strings.TrimSuffix(out.String(), "\n"), nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import "testing"
+
+func TestFilterJSON(t *testing.T) {
+	body := `{"total":3,"items":[{"id":1,"name":"a<b"},{"id":2,"name":"c"},{"id":3}],"meta":{"x":1,"y":"z"}}`
+
+	t.Run("paths", func(t *testing.T) {
+		for expr, expected := range map[string]string{
+			".":                      `{"items":[{"id":1,"name":"a<b"},{"id":2,"name":"c"},{"id":3}],"meta":{"x":1,"y":"z"},"total":3}`,
+			".total":                 "3",
+			".items[0].name":         `"a<b"`,
+			".items[-1]":             `{"id":3}`,
+			".items[5]":              "null",
+			".items[].id":            "1\n2\n3",
+			".items[1:] | .[].id":    "2\n3",
+			".meta[]":                "1\n\"z\"",
+			`.meta["y"]`:             `"z"`,
+			`."meta".x`:              "1",
+			".missing.key":           "null",
+			"$.items[*].id":          "1\n2\n3",
+			"$.items[:2]":            `[{"id":1,"name":"a<b"},{"id":2,"name":"c"}]`,
+			".meta | keys":           `["x","y"]`,
+			".items | length":        "3",
+			".items[].name | length": "3\n1\n0",
+		} {
+			out, err := filterJSON(expr, body, false)
+			if err != nil {
+				t.Errorf("expected no error of %s, got: %s", expr, err)
+				continue
+			}
+			if out != expected {
+				t.Errorf("expected %s of %s, got: %s", expected, expr, out)
+			}
+		}
+	})
+
+	t.Run("indent", func(t *testing.T) {
+		out, err := filterJSON(".meta", body, true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if expected := "{\n\t\"x\": 1,\n\t\"y\": \"z\"\n}"; out != expected {
+			t.Errorf("expected %q, got: %q", expected, out)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, expr := range []string{
+			"items", ".items[1:].id", ".total[]", ".items.id", ".items[x]", ".items[0", `."a`, ".total | keys", "$..a",
+		} {
+			if out, err := filterJSON(expr, body, false); err == nil {
+				t.Errorf("expected error of %s, got: %s", expr, out)
+			}
+		}
+		if _, err := filterJSON(".", "not json", false); err == nil {
+			t.Error("expected error of invalid JSON")
+		}
+	})
+}
@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// HistoryEntry is an executed request with its response.
+type HistoryEntry struct {
+	Time time.Time `json:"time"`
+	Session
+}
+
+// Create history entry of session: secrets of auth are dropped (placeholders of environment
+// are kept), the body of response is saved as is, it's highlighted again on restore.
+func NewHistoryEntry(t time.Time, ses Session, body string) HistoryEntry {
+	secret := func(s string) string {
+		if strings.HasPrefix(s, "{{") && strings.HasSuffix(s, "}}") {
+			return s
+		}
+		return ""
+	}
+	if a := ses.Request.Auth; a != nil {
+		auth := *a
+		auth.Password, auth.Token, auth.ClientSecret = secret(a.Password), secret(a.Token), secret(a.ClientSecret)
+		ses.Request.Auth = &auth
+	}
+	headers := make(map[string][]string, len(ses.Request.Headers))
+	for name, vals := range ses.Request.Headers {
+		if name == "Authorization" || name == "Proxy-Authorization" {
+			vals = slices.DeleteFunc(slices.Clone(vals), func(v string) bool { return secret(v) == "" })
+			if len(vals) == 0 {
+				continue
+			}
+		}
+		headers[name] = vals
+	}
+	ses.Request.Headers = headers
+	ses.Response.BodyLines = nil
+	ses.Response.RawBody = body
+	return HistoryEntry{t, ses}
+}
+
+// Status code of response.
+func (e *HistoryEntry) StatusCode() int {
+	code, _ := strconv.Atoi(strings.SplitN(e.Response.Status, " ", 2)[0])
+	return code
+}
+
+// Check if entry matches the filter: every word of filter should be equal to method,
+// be a prefix of status code (4, 40x, 404) or be a part of host.
+func (e *HistoryEntry) Matches(filter string) bool {
+	for _, w := range strings.Fields(filter) {
+		switch {
+		case strings.EqualFold(w, e.Request.Method):
+		case strings.HasPrefix(strconv.Itoa(e.StatusCode()), strings.TrimRight(strings.ToLower(w), "x")):
+		case strings.Contains(strings.ToLower(e.Request.Host), strings.ToLower(w)):
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// History of executed requests, it is stored on disk as JSON lines.
+type History struct {
+	path    string
+	limit   int // max number of entries, 0 means no limit
+	entries []HistoryEntry
+	matched []int // indexes of entries matched the filter, the newest first
+	cursor  int
+	filter  textinput.Model
+	style   []lipgloss.Style
+}
+
+func NewHistory(path string, limit int, colors ...lipgloss.Color) History {
+	f := textinput.New()
+	f.Prompt = "Filter: "
+	f.Placeholder = "POST 4xx example.com"
+	f.Width = 25
+	f.PromptStyle = lipgloss.NewStyle().Foreground(colors[0]).Bold(true)
+	f.PlaceholderStyle = lipgloss.NewStyle().Foreground(colors[1])
+	f.TextStyle = lipgloss.NewStyle().Foreground(colors[2])
+	return History{
+		path:   path,
+		limit:  limit,
+		filter: f,
+		style: []lipgloss.Style{
+			lipgloss.NewStyle().Foreground(colors[3]),
+			lipgloss.NewStyle().Foreground(colors[4]).Bold(true),
+		},
+	}
+}
+
+// Load history from disk, it's ok if history file is missed. Malformed entries are skipped,
+// the number of them is returned.
+func (h *History) Load() (int, error) {
+	if h.path == "" {
+		return 0, nil // history is disabled
+	}
+	f, err := os.Open(h.path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer f.Close()
+
+	h.entries = nil
+	skipped := 0
+	r := bufio.NewReader(f) // lines have no size limit: response bodies may be huge
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(bytes.TrimSpace(line)) > 0 {
+			var e HistoryEntry
+			if json.Unmarshal(line, &e) != nil {
+				skipped++
+			} else {
+				h.entries = append(h.entries, e)
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return skipped, err
+		}
+	}
+	h.applyFilter()
+	return skipped, nil
+}
+
+// Save all entries on disk, the history file is replaced atomically.
+func (h *History) save() error {
+	var b bytes.Buffer
+	for _, e := range h.entries {
+		line, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		b.Write(append(line, '\n'))
+	}
+	tmp := h.path + ".tmp"
+	if err := os.WriteFile(tmp, b.Bytes(), 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, h.path)
+}
+
+// Append entry to history and save it on disk.
+func (h *History) Append(e HistoryEntry) error {
+	h.entries = append(h.entries, e)
+	// the oldest entries are dropped when the limit is exceeded by 10%,
+	// so the file is not rewritten on every request
+	compact := h.limit > 0 && len(h.entries) > h.limit+h.limit/10
+	if compact {
+		h.entries = slices.Clone(h.entries[len(h.entries)-h.limit:])
+	}
+	h.applyFilter()
+	if h.path == "" {
+		return nil // history is disabled
+	}
+
+	if err := os.MkdirAll(filepath.Dir(h.path), 0o755); err != nil {
+		return err
+	}
+	if compact {
+		return h.save()
+	}
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(h.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Selected entry.
+func (h *History) Selected() (HistoryEntry, bool) {
+	if h.cursor >= len(h.matched) {
+		return HistoryEntry{}, false
+	}
+	return h.entries[h.matched[h.cursor]], true
+}
+
+// Move cursor up.
+func (h *History) Up() {
+	if h.cursor > 0 {
+		h.cursor--
+	}
+}
+
+// Move cursor down.
+func (h *History) Down() {
+	if h.cursor+1 < len(h.matched) {
+		h.cursor++
+	}
+}
+
+func (h *History) Focus() {
+	h.filter.Focus()
+}
+
+func (h *History) Blur() {
+	h.filter.Blur()
+}
+
+// Reset filter.
+func (h *History) ResetFilter() {
+	h.filter.Reset()
+	h.applyFilter()
+}
+
+func (h *History) applyFilter() {
+	h.matched = nil
+	for i := len(h.entries) - 1; i >= 0; i-- {
+		if h.entries[i].Matches(h.filter.Value()) {
+			h.matched = append(h.matched, i)
+		}
+	}
+	if h.cursor >= len(h.matched) {
+		h.cursor = max(len(h.matched)-1, 0)
+	}
+}
+
+func (h History) Update(msg tea.Msg) (History, tea.Cmd) {
+	var cmd tea.Cmd
+	v := h.filter.Value()
+	h.filter, cmd = h.filter.Update(msg)
+	if v != h.filter.Value() {
+		h.cursor = 0
+		h.applyFilter()
+	}
+	return h, cmd
+}
+
+// Render filter and page of entries which contains the cursor.
+func (h History) View(width, height int) string {
+	lines := []string{h.filter.View()}
+	limit := height - 1
+	if limit < 1 {
+		limit = 1
+	}
+	start := h.cursor / limit * limit
+	for i := start; i < len(h.matched) && i < start+limit; i++ {
+		e := h.entries[h.matched[i]]
+		line := e.Time.Format("01-02 15:04:05") + " " + strconv.Itoa(e.StatusCode()) + " " +
+			e.Request.Method + " " + e.Request.Host + e.Request.UrlPath + " " + e.ResTime
+		style := h.style[0]
+		if i == h.cursor {
+			style = h.style[1]
+		}
+		lines = append(lines, style.MaxWidth(width).Render(line))
+	}
+	if len(h.matched) == 0 {
+		lines = append(lines, h.style[0].Render("no entries"))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
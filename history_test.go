@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func newTestHistory(path string, limit int) History {
+	return NewHistory(path, limit, make([]lipgloss.Color, 5)...)
+}
+
+func TestHistory(t *testing.T) {
+	entry := func(i int) HistoryEntry {
+		return HistoryEntry{
+			Time: time.Unix(int64(i), 0),
+			Session: Session{
+				Request:  Request{Method: "GET", Host: "example.com", UrlPath: "/" + strconv.Itoa(i)},
+				Response: Response{Status: "200 OK"},
+			},
+		}
+	}
+
+	t.Run("load", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.jsonl")
+		big := entry(2)
+		big.Response.RawBody = strings.Repeat("x", 100*1024) // larger than default buffer of scanner
+		var lines []string
+		for _, e := range []HistoryEntry{entry(1), big} {
+			b, _ := json.Marshal(e)
+			lines = append(lines, string(b))
+		}
+		lines = append(lines, "{malformed", "", `{"time":"bad"}`)
+		if err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		h := newTestHistory(path, 0)
+		skipped, err := h.Load()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if skipped != 2 || len(h.entries) != 2 {
+			t.Errorf("expected 2 entries and 2 skipped lines, got: %d entries, %d skipped", len(h.entries), skipped)
+		}
+		if e, _ := h.Selected(); e.Request.UrlPath != "/2" || len(e.Response.RawBody) != 100*1024 {
+			t.Errorf("expected the newest entry /2 with its body, got: %s", e.Request.UrlPath)
+		}
+	})
+
+	t.Run("limit", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "history.jsonl")
+		h := newTestHistory(path, 10)
+		for i := 1; i <= 11; i++ {
+			if err := h.Append(entry(i)); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(h.entries) != 11 {
+			t.Errorf("expected 11 entries within slack of limit, got: %d", len(h.entries))
+		}
+		if err := h.Append(entry(12)); err != nil {
+			t.Fatal(err)
+		}
+		if len(h.entries) != 10 || h.entries[0].Request.UrlPath != "/3" {
+			t.Errorf("expected the last 10 entries from /3, got: %d", len(h.entries))
+		}
+
+		loaded := newTestHistory(path, 10)
+		if _, err := loaded.Load(); err != nil {
+			t.Fatal(err)
+		}
+		if len(loaded.entries) != 10 || loaded.entries[9].Request.UrlPath != "/12" {
+			t.Errorf("expected 10 entries on disk, got: %d", len(loaded.entries))
+		}
+	})
+
+	t.Run("filter", func(t *testing.T) {
+		h := newTestHistory("", 0)
+		e := entry(1)
+		e.Request.Method, e.Response.Status = "POST", "404 Not Found"
+		h.Append(e)
+		h.Append(entry(2))
+		for filter, expected := range map[string]int{"": 2, "post": 1, "4xx": 1, "example 20": 1, "other.com": 0} {
+			h.filter.SetValue(filter)
+			h.applyFilter()
+			if len(h.matched) != expected {
+				t.Errorf("expected %d entries of filter %q, got: %d", expected, filter, len(h.matched))
+			}
+		}
+	})
+}
+
+func TestNewHistoryEntry(t *testing.T) {
+	ses := Session{
+		Request: Request{
+			Headers: map[string][]string{
+				"Authorization": {"Bearer secret", "{{token}}"},
+				"Accept":        {"*/*"},
+			},
+			Auth: &Auth{Type: authOAuth2, ClientID: "id", ClientSecret: "secret", Token: "{{refresh}}"},
+		},
+		Response: Response{BodyLines: []string{"\x1b[1m{}\x1b[0m"}},
+	}
+	e := NewHistoryEntry(time.Now(), ses, "{}")
+	if v := e.Request.Headers["Authorization"]; len(v) != 1 || v[0] != "{{token}}" {
+		t.Errorf("expected only placeholder of Authorization header, got: %v", v)
+	}
+	if e.Request.Auth.ClientSecret != "" || e.Request.Auth.Token != "{{refresh}}" || e.Request.Auth.ClientID != "id" {
+		t.Errorf("expected secrets of auth are dropped, got: %#v", e.Request.Auth)
+	}
+	if ses.Request.Auth.ClientSecret != "secret" || len(ses.Request.Headers["Authorization"]) != 2 {
+		t.Error("expected session is untouched")
+	}
+	if e.Response.BodyLines != nil || e.Response.RawBody != "{}" {
+		t.Errorf("expected plain body, got: %q %q", e.Response.BodyLines, e.Response.RawBody)
+	}
+}
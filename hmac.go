@@ -0,0 +1,173 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/md5"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"hash"
+	"io"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Signer is a custom HMAC signature scheme: the string to sign is made of template
+// with {{name}} placeholders of request parts, it's signed by the key of request auth
+// and the signature is set to the target header.
+//
+// Placeholders: method, host, path, query, content_type, timestamp, nonce, key_id,
+// body_hash (hash of body by the algorithm of signer), header.<Name> and signature
+// (the last one is available only in the value of target header).
+type Signer struct {
+	StringToSign    string `json:"StringToSign"`
+	Algorithm       string `json:"Algorithm"`       // md5, sha1, sha256 (default) or sha512
+	Encoding        string `json:"Encoding"`        // hex (default), base64 or base64url
+	Header          string `json:"Header"`          // target header of signature
+	HeaderValue     string `json:"HeaderValue"`     // template of header value, default is {{signature}}
+	Timestamp       string `json:"Timestamp"`       // unix (default), unix_ms, rfc3339 or http
+	TimestampHeader string `json:"TimestampHeader"` // optional header of timestamp
+	NonceHeader     string `json:"NonceHeader"`     // optional header of nonce
+}
+
+// Signers of config.
+var signers map[string]Signer
+
+// Names of signers in alphabetical order.
+func signerNames() []string {
+	var names []string
+	for name := range signers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+func (s *Signer) hash() (func() hash.Hash, error) {
+	switch strings.ToLower(s.Algorithm) {
+	case "", "sha256":
+		return sha256.New, nil
+	case "sha512":
+		return sha512.New, nil
+	case "sha1":
+		return sha1.New, nil
+	case "md5":
+		return md5.New, nil
+	}
+	return nil, errors.New("unknown hash algorithm of signer: " + s.Algorithm)
+}
+
+func (s *Signer) encode(b []byte) (string, error) {
+	switch strings.ToLower(s.Encoding) {
+	case "", "hex":
+		return hex.EncodeToString(b), nil
+	case "base64":
+		return base64.StdEncoding.EncodeToString(b), nil
+	case "base64url":
+		return base64.RawURLEncoding.EncodeToString(b), nil
+	}
+	return "", errors.New("unknown encoding of signer: " + s.Encoding)
+}
+
+func (s *Signer) timestamp(now time.Time) (string, error) {
+	switch strings.ToLower(s.Timestamp) {
+	case "", "unix":
+		return strconv.FormatInt(now.Unix(), 10), nil
+	case "unix_ms":
+		return strconv.FormatInt(now.UnixMilli(), 10), nil
+	case "rfc3339":
+		return now.UTC().Format(time.RFC3339), nil
+	case "http":
+		return now.UTC().Format(http.TimeFormat), nil
+	}
+	return "", errors.New("unknown timestamp format of signer: " + s.Timestamp)
+}
+
+// Expand {{name}} placeholders of template by the given variables and headers of request,
+// unknown placeholders are left as is.
+func expandSignerTemplate(t string, vars map[string]string, h http.Header) string {
+	return envVarRegexp.ReplaceAllStringFunc(t, func(v string) string {
+		name := envVarRegexp.FindStringSubmatch(v)[1]
+		if val, ok := vars[name]; ok {
+			return val
+		}
+		if hn, ok := strings.CutPrefix(name, "header."); ok {
+			return h.Get(hn)
+		}
+		return v
+	})
+}
+
+// Sign request by HMAC signer, the body of request is read and restored.
+// Returns the string to sign.
+func signHMAC(r *http.Request, a Auth, s Signer, now time.Time) (string, error) {
+	if s.Header == "" {
+		return "", errors.New("target header of signer " + a.Name + " is not set")
+	}
+	newHash, err := s.hash()
+	if err != nil {
+		return "", err
+	}
+	ts, err := s.timestamp(now)
+	if err != nil {
+		return "", err
+	}
+
+	var body []byte
+	if r.Body != nil {
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return "", err
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	h := newHash()
+	h.Write(body)
+	bodyHash, err := s.encode(h.Sum(nil))
+	if err != nil {
+		return "", err
+	}
+
+	b := make([]byte, 16)
+	rand.Read(b)
+	nonce := hex.EncodeToString(b)
+
+	if s.TimestampHeader != "" {
+		r.Header.Set(s.TimestampHeader, ts)
+	}
+	if s.NonceHeader != "" {
+		r.Header.Set(s.NonceHeader, nonce)
+	}
+
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	vars := map[string]string{
+		"method": r.Method, "host": host, "path": r.URL.EscapedPath(), "query": r.URL.RawQuery,
+		"content_type": r.Header.Get("Content-Type"), "timestamp": ts, "nonce": nonce,
+		"key_id": a.User, "body_hash": bodyHash,
+	}
+	stringToSign := expandSignerTemplate(s.StringToSign, vars, r.Header)
+
+	mac := hmac.New(newHash, []byte(a.Password))
+	mac.Write([]byte(stringToSign))
+	if vars["signature"], err = s.encode(mac.Sum(nil)); err != nil {
+		return "", err
+	}
+
+	headerValue := s.HeaderValue
+	if headerValue == "" {
+		headerValue = "{{signature}}"
+	}
+	r.Header.Set(s.Header, expandSignerTemplate(headerValue, vars, r.Header))
+	return stringToSign, nil
+}
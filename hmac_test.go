@@ -0,0 +1,57 @@
+package main
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignHMAC(t *testing.T) {
+	a := Auth{Type: authHMAC, Name: "partner", User: "id1", Password: "secret"}
+	now := time.Unix(1700000000, 0)
+
+	t.Run("template", func(t *testing.T) {
+		s := Signer{
+			StringToSign:    "{{method}}\n{{path}}?{{query}}\n{{timestamp}}\n{{body_hash}}\n{{header.X-Api-Key}}",
+			Encoding:        "base64",
+			Header:          "Authorization",
+			HeaderValue:     "HMAC {{key_id}}:{{signature}}",
+			TimestampHeader: "X-Timestamp",
+		}
+		r, _ := http.NewRequest("POST", "http://localhost/api/v1?a=1", strings.NewReader(`{"a":1}`))
+		r.Header.Set("X-Api-Key", "key")
+		stringToSign, err := signHMAC(r, a, s, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bodyHash := sha256.Sum256([]byte(`{"a":1}`))
+		expected := "POST\n/api/v1?a=1\n1700000000\n" + base64.StdEncoding.EncodeToString(bodyHash[:]) + "\nkey"
+		if stringToSign != expected {
+			t.Errorf("expected string to sign:\n%s\ngot:\n%s", expected, stringToSign)
+		}
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write([]byte(expected))
+		expected = "HMAC id1:" + base64.StdEncoding.EncodeToString(mac.Sum(nil))
+		if v := r.Header.Get("Authorization"); v != expected {
+			t.Errorf("expected Authorization: %s, got: %s", expected, v)
+		}
+		if v := r.Header.Get("X-Timestamp"); v != "1700000000" {
+			t.Errorf("expected X-Timestamp: 1700000000, got: %s", v)
+		}
+	})
+
+	t.Run("invalid signer", func(t *testing.T) {
+		r, _ := http.NewRequest("GET", "http://localhost/", nil)
+		if _, err := signHMAC(r, a, Signer{Header: "X-Sig", Algorithm: "sha3"}, now); err == nil {
+			t.Error("expected error of unknown algorithm")
+		}
+		if _, err := signHMAC(r, a, Signer{}, now); err == nil {
+			t.Error("expected error of missed target header")
+		}
+	})
+}
@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"errors"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Nodes of JSON tree deeper than this one are collapsed initially.
+const treeExpandDepth = 2
+
+// Keys which may be written in jq-like path without quotes.
+var plainKeyRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// Node of JSON tree: object, array or scalar value.
+type treeNode struct {
+	key      string     // key of object member or index of array element, empty for root
+	path     string     // jq-like path of node
+	delim    json.Delim // '{' of object, '[' of array, 0 of scalar value
+	value    any
+	children []*treeNode
+	parent   *treeNode
+	depth    int
+	expanded bool
+}
+
+// JSONTree is a collapsible tree of JSON values (a filter may produce several ones),
+// the visible nodes are rendered line by line, one node per line.
+type JSONTree struct {
+	roots  []*treeNode
+	rows   []*treeNode // visible nodes
+	cursor int
+}
+
+// Styles of JSON tree: key, string, number, literal (bool, null), summary and cursor,
+// they are set by theme.
+var treeStyles [6]lipgloss.Style
+
+// Create JSON tree of stream of JSON values, the order of object keys is kept.
+func NewJSONTree(body string) (*JSONTree, error) {
+	var t JSONTree
+	d := json.NewDecoder(strings.NewReader(body))
+	d.UseNumber()
+	for {
+		n, err := decodeTreeNode(d, nil, "", "")
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		t.roots = append(t.roots, n)
+	}
+	if len(t.roots) == 0 {
+		return nil, errors.New("body is empty")
+	}
+	t.refresh()
+	return &t, nil
+}
+
+// Path of object member.
+func keyPath(path, key string) string {
+	if plainKeyRe.MatchString(key) {
+		return path + "." + key
+	}
+	return path + "[" + strconv.Quote(key) + "]"
+}
+
+// Decode the next value of JSON stream to tree node.
+func decodeTreeNode(d *json.Decoder, parent *treeNode, key, path string) (*treeNode, error) {
+	tok, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &treeNode{key: key, path: path, parent: parent}
+	if parent != nil {
+		n.depth = parent.depth + 1
+	}
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		n.value = tok
+		return n, nil
+	}
+	n.delim = delim
+	for i := 0; d.More(); i++ {
+		k, p := strconv.Itoa(i), path+"["+strconv.Itoa(i)+"]"
+		if delim == '{' {
+			tok, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			k = tok.(string)
+			p = keyPath(path, k)
+		}
+		c, err := decodeTreeNode(d, n, k, p)
+		if err != nil {
+			return nil, err
+		}
+		n.children = append(n.children, c)
+	}
+	if _, err := d.Token(); err != nil { // closing delimiter
+		return nil, err
+	}
+	n.expanded = n.depth < treeExpandDepth && len(n.children) > 0
+	return n, nil
+}
+
+// Collect visible nodes.
+func (t *JSONTree) refresh() {
+	t.rows = t.rows[:0]
+	var walk func(n *treeNode)
+	walk = func(n *treeNode) {
+		t.rows = append(t.rows, n)
+		if n.expanded {
+			for _, c := range n.children {
+				walk(c)
+			}
+		}
+	}
+	for _, n := range t.roots {
+		walk(n)
+	}
+	t.cursor = min(t.cursor, len(t.rows)-1)
+}
+
+// Summary of object or array: item counts.
+func (n *treeNode) summary() string {
+	noun := "item"
+	if n.delim == '{' {
+		noun = "key"
+	}
+	if len(n.children) != 1 {
+		noun += "s"
+	}
+	return strconv.Itoa(len(n.children)) + " " + noun
+}
+
+// Render line of node, the line starts with two spaces: the place of cursor marker.
+func (t *JSONTree) line(n *treeNode) string {
+	var b strings.Builder
+	b.WriteString("  ")
+	b.WriteString(strings.Repeat("  ", n.depth))
+	switch {
+	case len(n.children) == 0: // scalar value, empty object or array
+		b.WriteString("  ")
+	case n.expanded:
+		b.WriteString("▾ ")
+	default:
+		b.WriteString("▸ ")
+	}
+	if n.parent != nil {
+		key := n.key
+		if n.parent.delim == '{' {
+			key = strconv.Quote(key)
+		}
+		b.WriteString(treeStyles[0].Render(key) + ": ")
+	}
+
+	switch v := n.value.(type) {
+	case string:
+		var s bytes.Buffer
+		e := json.NewEncoder(&s)
+		e.SetEscapeHTML(false)
+		e.Encode(v)
+		b.WriteString(treeStyles[1].Render(strings.TrimSuffix(s.String(), "\n")))
+	case json.Number:
+		b.WriteString(treeStyles[2].Render(v.String()))
+	case bool:
+		b.WriteString(treeStyles[3].Render(strconv.FormatBool(v)))
+	case nil:
+		if n.delim == 0 {
+			b.WriteString(treeStyles[3].Render("null"))
+			break
+		}
+		brackets := "{}"
+		if n.delim == '[' {
+			brackets = "[]"
+		}
+		if n.expanded || len(n.children) == 0 {
+			b.WriteString(treeStyles[4].Render(brackets))
+			break
+		}
+		b.WriteString(treeStyles[4].Render(brackets[:1] + "…" + brackets[1:] + " " + n.summary()))
+	}
+	return b.String()
+}
+
+// Lines of visible nodes.
+func (t *JSONTree) Lines() []string {
+	lines := make([]string, len(t.rows))
+	for i, n := range t.rows {
+		lines[i] = t.line(n)
+	}
+	return lines
+}
+
+// Index of line of the node under cursor.
+func (t *JSONTree) Cursor() int {
+	return t.cursor
+}
+
+// Mark line of the node under cursor.
+func (t *JSONTree) CursorLine(line string) string {
+	return treeStyles[5].Render("› ") + strings.TrimPrefix(line, "  ")
+}
+
+// Move cursor by delta of lines.
+func (t *JSONTree) Move(delta int) {
+	t.cursor = max(min(t.cursor+delta, len(t.rows)-1), 0)
+}
+
+// Path of the node under cursor.
+func (t *JSONTree) Path() string {
+	return cmp.Or(t.rows[t.cursor].path, ".")
+}
+
+// Expand node under cursor, move to its first child if it's expanded already.
+// It returns true if lines of tree are changed.
+func (t *JSONTree) Expand() bool {
+	n := t.rows[t.cursor]
+	switch {
+	case len(n.children) == 0:
+	case !n.expanded:
+		n.expanded = true
+		t.refresh()
+		return true
+	default:
+		t.cursor++
+	}
+	return false
+}
+
+// Collapse node under cursor, move to its parent if it's collapsed already.
+// It returns true if lines of tree are changed.
+func (t *JSONTree) Collapse() bool {
+	n := t.rows[t.cursor]
+	if n.expanded {
+		n.expanded = false
+		t.refresh()
+		return true
+	}
+	if n.parent != nil {
+		for t.rows[t.cursor] != n.parent {
+			t.cursor--
+		}
+	}
+	return false
+}
+
+// Toggle node under cursor. It returns true if lines of tree are changed.
+func (t *JSONTree) Toggle() bool {
+	if t.rows[t.cursor].expanded {
+		return t.Collapse()
+	}
+	return t.Expand()
+}
@@ -0,0 +1,87 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestJSONTree(t *testing.T) {
+	body := `{"name":"rhttp","tags":["a","b"],"meta":{"stars":5,"fork":false,"user-id":null,"deps":{"x":1}},"empty":[]}`
+	lines := func(tree *JSONTree) []string {
+		var plain []string
+		for _, line := range tree.Lines() {
+			plain = append(plain, stripEscapes(line))
+		}
+		return plain
+	}
+
+	t.Run("lines", func(t *testing.T) {
+		tree, err := NewJSONTree(body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := []string{
+			`  ▾ {}`,
+			`      "name": "rhttp"`,
+			`    ▾ "tags": []`,
+			`        0: "a"`,
+			`        1: "b"`,
+			`    ▾ "meta": {}`,
+			`        "stars": 5`,
+			`        "fork": false`,
+			`        "user-id": null`,
+			`      ▸ "deps": {…} 1 key`,
+			`      "empty": []`,
+		}
+		if got := lines(tree); !slices.Equal(got, expected) {
+			t.Errorf("expected lines:\n%q\ngot:\n%q", expected, got)
+		}
+	})
+
+	t.Run("navigation", func(t *testing.T) {
+		tree, _ := NewJSONTree(body)
+		if tree.Path() != "." {
+			t.Errorf("expected path of root ., got: %s", tree.Path())
+		}
+		tree.Move(3)
+		if tree.Path() != ".tags[0]" {
+			t.Errorf("expected path .tags[0], got: %s", tree.Path())
+		}
+		if tree.Collapse() || tree.Path() != ".tags" {
+			t.Errorf("expected move to parent .tags, got: %s", tree.Path())
+		}
+		if !tree.Collapse() || lines(tree)[2] != `    ▸ "tags": […] 2 items` || len(tree.Lines()) != 9 {
+			t.Errorf("expected collapsed .tags, got: %q", lines(tree))
+		}
+		tree.Move(4)
+		if tree.Path() != `.meta["user-id"]` {
+			t.Errorf(`expected path .meta["user-id"], got: %s`, tree.Path())
+		}
+		tree.Move(1)
+		if !tree.Toggle() || tree.Expand() || tree.Path() != ".meta.deps.x" {
+			t.Errorf("expected expanded .meta.deps and move to its child, got: %s", tree.Path())
+		}
+		tree.Move(100)
+		if tree.Path() != ".empty" || tree.Toggle() {
+			t.Errorf("expected cursor at the last node .empty, got: %s", tree.Path())
+		}
+	})
+
+	t.Run("stream", func(t *testing.T) {
+		tree, err := NewJSONTree("1\n\"a\"\n{\"b\":[]}")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := lines(tree); !slices.Equal(got, []string{`    1`, `    "a"`, `  ▾ {}`, `      "b": []`}) {
+			t.Errorf("expected three roots, got: %q", got)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		for _, s := range []string{"", "plain text", `{"a":`, `[1,]`} {
+			if _, err := NewJSONTree(s); err == nil {
+				t.Errorf("expected error of %q", s)
+			}
+		}
+	})
+}
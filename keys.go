@@ -1,6 +1,9 @@
 package main
 
 import (
+	"slices"
+	"strings"
+
 	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/lipgloss"
@@ -9,7 +12,10 @@ import (
 type KeyMap struct {
 	Next, Prev, Quit, Help, Run, FullScreen, PageUp, PageDown, Up, Down, Enter,
 	Delete, Autocomplete, LoadSession, SaveSession, ToggleCheckbox, ToggleJSON, SaveJSON,
-	Payload key.Binding
+	Payload, Cancel, SwitchEnv, ImportCurl, Export, History, Collection, Rename, Duplicate,
+	SaveToCollection, BodyType, Signature, Proxy, TLS, Conn, Redirects, FollowRedirect, CookieJar,
+	Search, NextMatch, PrevMatch, SearchRegex, SearchCase, Filter, JSONTree, Expand, Collapse,
+	CopyPath key.Binding
 }
 
 func (k KeyMap) ShortHelp() []key.Binding {
@@ -17,9 +23,12 @@ func (k KeyMap) ShortHelp() []key.Binding {
 }
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
-		{k.Next, k.Prev, k.Enter, k.Run, k.Delete, k.ToggleCheckbox},
-		{k.FullScreen, k.Help, k.Quit, k.LoadSession, k.SaveSession, k.Autocomplete},
-		{k.ToggleJSON, k.SaveJSON, k.Payload, k.PageDown, k.PageUp},
+		{k.Next, k.Prev, k.Enter, k.Run, k.Cancel, k.Delete, k.ToggleCheckbox},
+		{k.FullScreen, k.Help, k.Quit, k.LoadSession, k.SaveSession, k.Autocomplete, k.ImportCurl, k.Export},
+		{k.ToggleJSON, k.SaveJSON, k.BodyType, k.Payload, k.PageDown, k.PageUp, k.SwitchEnv, k.History},
+		{k.Collection, k.SaveToCollection, k.Rename, k.Duplicate, k.Signature, k.Proxy, k.TLS, k.Conn},
+		{k.Redirects, k.FollowRedirect, k.CookieJar, k.Search, k.NextMatch, k.PrevMatch, k.SearchRegex, k.SearchCase, k.Filter},
+		{k.JSONTree, k.Expand, k.Collapse, k.CopyPath},
 	}
 }
 
@@ -60,6 +69,10 @@ var keys = KeyMap{
 		key.WithKeys("ctrl+g"),
 		key.WithHelp("Ctrl+g", "run request"),
 	),
+	Cancel: key.NewBinding(
+		key.WithKeys("ctrl+x"),
+		key.WithHelp("Ctrl+x", "cancel request"),
+	),
 	FullScreen: key.NewBinding(
 		key.WithKeys("ctrl+f"),
 		key.WithHelp("Ctrl+f", "toggle full screen"),
@@ -72,6 +85,102 @@ var keys = KeyMap{
 		key.WithKeys("pgdown"),
 		key.WithHelp("PgDn", "scroll down body of response"),
 	),
+	Up: key.NewBinding(
+		key.WithKeys("up"),
+		key.WithHelp("↑", "previous item of list"),
+	),
+	Down: key.NewBinding(
+		key.WithKeys("down"),
+		key.WithHelp("↓", "next item of list"),
+	),
+	Proxy: key.NewBinding(
+		key.WithKeys("alt+p"),
+		key.WithHelp("Alt+p", "set proxy of request"),
+	),
+	TLS: key.NewBinding(
+		key.WithKeys("alt+l"),
+		key.WithHelp("Alt+l", "set TLS options of request"),
+	),
+	Redirects: key.NewBinding(
+		key.WithKeys("alt+w"),
+		key.WithHelp("Alt+w", "toggle redirect chain"),
+	),
+	FollowRedirect: key.NewBinding(
+		key.WithKeys("alt+o"),
+		key.WithHelp("Alt+o", "follow redirect"),
+	),
+	Search: key.NewBinding(
+		key.WithKeys("alt+/"),
+		key.WithHelp("Alt+/", "search in response body"),
+	),
+	NextMatch: key.NewBinding(
+		key.WithKeys("alt+."),
+		key.WithHelp("Alt+.", "next match of search"),
+	),
+	PrevMatch: key.NewBinding(
+		key.WithKeys("alt+,"),
+		key.WithHelp("Alt+,", "prev match of search"),
+	),
+	SearchRegex: key.NewBinding(
+		key.WithKeys("alt+z"),
+		key.WithHelp("Alt+z", "toggle regex search"),
+	),
+	SearchCase: key.NewBinding(
+		key.WithKeys("alt+m"),
+		key.WithHelp("Alt+m", "toggle match case of search"),
+	),
+	Filter: key.NewBinding(
+		key.WithKeys("alt+q"),
+		key.WithHelp("Alt+q", "filter JSON response (jq, JSONPath)"),
+	),
+	JSONTree: key.NewBinding(
+		key.WithKeys("alt+v"),
+		key.WithHelp("Alt+v", "toggle JSON tree of response"),
+	),
+	Expand: key.NewBinding(
+		key.WithKeys("right"),
+		key.WithHelp("→", "expand node of JSON tree"),
+	),
+	Collapse: key.NewBinding(
+		key.WithKeys("left"),
+		key.WithHelp("←", "collapse node of JSON tree"),
+	),
+	CopyPath: key.NewBinding(
+		key.WithKeys("alt+y"),
+		key.WithHelp("Alt+y", "copy path of JSON tree node"),
+	),
+	CookieJar: key.NewBinding(
+		key.WithKeys("alt+k"),
+		key.WithHelp("Alt+k", "toggle cookie jar"),
+	),
+	Conn: key.NewBinding(
+		key.WithKeys("alt+n"),
+		key.WithHelp("Alt+n", "toggle timing and connection"),
+	),
+	Signature: key.NewBinding(
+		key.WithKeys("alt+g"),
+		key.WithHelp("Alt+g", "toggle signature debug"),
+	),
+	History: key.NewBinding(
+		key.WithKeys("alt+h"),
+		key.WithHelp("Alt+h", "toggle history"),
+	),
+	Collection: key.NewBinding(
+		key.WithKeys("alt+c"),
+		key.WithHelp("Alt+c", "toggle collection"),
+	),
+	SaveToCollection: key.NewBinding(
+		key.WithKeys("alt+s"),
+		key.WithHelp("Alt+s", "save to collection"),
+	),
+	Rename: key.NewBinding(
+		key.WithKeys("alt+r"),
+		key.WithHelp("Alt+r", "rename collection item"),
+	),
+	Duplicate: key.NewBinding(
+		key.WithKeys("alt+u"),
+		key.WithHelp("Alt+u", "duplicate collection item"),
+	),
 	Enter: key.NewBinding(
 		key.WithKeys("enter"),
 		key.WithHelp("Enter", "set value"),
@@ -82,16 +191,119 @@ var keys = KeyMap{
 	),
 	ToggleJSON: key.NewBinding(
 		key.WithKeys("ctrl+j"),
-		key.WithHelp("Ctrl+j", "toggle JSON payload"),
+		key.WithHelp("Ctrl+j", "toggle body editor"),
 	),
 	SaveJSON: key.NewBinding(
 		key.WithKeys("alt+enter"),
-		key.WithHelp("Alt+enter", "save JSON payload"),
+		key.WithHelp("Alt+enter", "save body"),
+	),
+	BodyType: key.NewBinding(
+		key.WithKeys("alt+t"),
+		key.WithHelp("Alt+t", "switch body type"),
 	),
 	Payload: key.NewBinding(
 		key.WithKeys("ctrl+p"),
 		key.WithHelp("Ctrl+p", "add payload"),
 	),
+	ImportCurl: key.NewBinding(
+		key.WithKeys("alt+i"),
+		key.WithHelp("Alt+i", "import curl command"),
+	),
+	Export: key.NewBinding(
+		key.WithKeys("alt+x"),
+		key.WithHelp("Alt+x", "export request"),
+	),
+	SwitchEnv: key.NewBinding(
+		key.WithKeys("alt+e"),
+		key.WithHelp("Alt+e", "switch environment"),
+	),
+}
+
+// Bindings of key map by action names.
+func (k *KeyMap) actions() map[string]*key.Binding {
+	return map[string]*key.Binding{
+		"Next": &k.Next, "Prev": &k.Prev, "Quit": &k.Quit, "Help": &k.Help, "Run": &k.Run,
+		"FullScreen": &k.FullScreen, "PageUp": &k.PageUp, "PageDown": &k.PageDown, "Up": &k.Up,
+		"Down": &k.Down, "Enter": &k.Enter, "Delete": &k.Delete, "Autocomplete": &k.Autocomplete,
+		"LoadSession": &k.LoadSession, "SaveSession": &k.SaveSession,
+		"ToggleCheckbox": &k.ToggleCheckbox, "ToggleJSON": &k.ToggleJSON, "SaveJSON": &k.SaveJSON,
+		"Payload": &k.Payload, "Cancel": &k.Cancel, "SwitchEnv": &k.SwitchEnv,
+		"ImportCurl": &k.ImportCurl, "Export": &k.Export, "History": &k.History,
+		"Collection": &k.Collection, "Rename": &k.Rename, "Duplicate": &k.Duplicate,
+		"SaveToCollection": &k.SaveToCollection, "BodyType": &k.BodyType, "Signature": &k.Signature,
+		"Proxy": &k.Proxy, "TLS": &k.TLS, "Conn": &k.Conn, "Redirects": &k.Redirects,
+		"FollowRedirect": &k.FollowRedirect, "CookieJar": &k.CookieJar, "Search": &k.Search,
+		"NextMatch": &k.NextMatch, "PrevMatch": &k.PrevMatch, "SearchRegex": &k.SearchRegex,
+		"SearchCase": &k.SearchCase, "Filter": &k.Filter, "JSONTree": &k.JSONTree,
+		"Expand": &k.Expand, "Collapse": &k.Collapse, "CopyPath": &k.CopyPath,
+	}
+}
+
+// Help text of key: ctrl+g → Ctrl+g, tab → Tab, alt++ → Alt++.
+func keyHelp(k string) string {
+	if k == " " {
+		return "Space"
+	}
+	capitalize := func(s string) string {
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	}
+	// the last part is the key itself, it may be "+" as well
+	i := strings.LastIndex(k[:max(len(k)-1, 0)], "+")
+	if i < 0 {
+		return capitalize(k)
+	}
+	parts := strings.Split(k[:i], "+")
+	for j, p := range parts {
+		parts[j] = capitalize(p)
+	}
+	return strings.Join(append(parts, k[i+1:]), "+")
+}
+
+// Create key map: the default bindings are overridden by the given keys of actions.
+// Returns warnings about unknown actions and keys bound to several actions.
+func NewKeyMap(bindings map[string][]string) (KeyMap, []string) {
+	km := keys
+	actions := km.actions()
+	var names, warns []string
+	for name := range bindings {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		b, ok := actions[name]
+		if !ok {
+			warns = append(warns, `unknown key binding action "`+name+`"`)
+			continue
+		}
+		ks := slices.DeleteFunc(slices.Clone(bindings[name]), func(s string) bool { return s == "" })
+		if len(ks) == 0 {
+			warns = append(warns, `no keys of key binding action "`+name+`"`)
+			continue
+		}
+		*b = key.NewBinding(key.WithKeys(ks...), key.WithHelp(keyHelp(ks[0]), b.Help().Desc))
+	}
+
+	bound := make(map[string][]string) // actions by keys
+	var ks []string
+	for name, b := range actions {
+		for _, k := range b.Keys() {
+			if len(bound[k]) == 0 {
+				ks = append(ks, k)
+			}
+			bound[k] = append(bound[k], name)
+		}
+	}
+	slices.Sort(ks)
+	for _, k := range ks {
+		if len(bound[k]) > 1 {
+			slices.Sort(bound[k])
+			warns = append(warns, `key "`+k+`" is bound to several actions: `+strings.Join(bound[k], ", "))
+		}
+	}
+	return km, warns
 }
 
 // Helper struct for linking together help and key bindings.
@@ -101,11 +313,11 @@ type KeyStroke struct {
 }
 
 // Create new instance of [KeyStroke].
-func NewKeyStroke(keyStyle, descStyle lipgloss.Color) KeyStroke {
+func NewKeyStroke(km KeyMap, keyStyle, descStyle lipgloss.Color) KeyStroke {
 	h := help.New()
 	h.Styles.FullKey = h.Styles.FullKey.Foreground(keyStyle)
 	h.Styles.FullDesc = h.Styles.FullDesc.Foreground(descStyle)
 	h.Styles.ShortKey = h.Styles.FullKey
 	h.Styles.ShortDesc = h.Styles.FullDesc
-	return KeyStroke{keys: keys, help: h}
+	return KeyStroke{keys: km, help: h}
 }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestNewKeyMap(t *testing.T) {
+	t.Run("defaults", func(t *testing.T) {
+		if _, warns := NewKeyMap(nil); len(warns) > 0 {
+			t.Errorf("expected no warnings of default key map, got: %v", warns)
+		}
+	})
+
+	t.Run("remap", func(t *testing.T) {
+		km, warns := NewKeyMap(map[string][]string{"Help": {"f1", "alt+?"}, "ToggleJSON": {"alt+j"}})
+		if len(warns) > 0 {
+			t.Errorf("expected no warnings, got: %v", warns)
+		}
+		if !slices.Equal(km.Help.Keys(), []string{"f1", "alt+?"}) || km.Help.Help().Key != "F1" ||
+			km.Help.Help().Desc != "toggle help" {
+			t.Errorf("expected Help bound to f1, alt+?, got: %v %v", km.Help.Keys(), km.Help.Help())
+		}
+		if km.ToggleJSON.Help().Key != "Alt+j" {
+			t.Errorf("expected help key Alt+j, got: %s", km.ToggleJSON.Help().Key)
+		}
+		if keys.Help.Help().Key != "Ctrl+h" {
+			t.Errorf("expected default key map is untouched, got: %s", keys.Help.Help().Key)
+		}
+	})
+
+	t.Run("warnings", func(t *testing.T) {
+		_, warns := NewKeyMap(map[string][]string{"Jump": {"f2"}, "Rename": {"ctrl+d"}, "Run": {}})
+		expected := []string{
+			`no keys of key binding action "Run"`,
+			`unknown key binding action "Jump"`,
+			`key "ctrl+d" is bound to several actions: Delete, Rename`,
+		}
+		for _, w := range expected {
+			if !slices.Contains(warns, w) {
+				t.Errorf("expected warning %q, got: %v", w, warns)
+			}
+		}
+	})
+}
+
+func TestKeyHelp(t *testing.T) {
+	for k, expected := range map[string]string{
+		"ctrl+g": "Ctrl+g", "tab": "Tab", " ": "Space", "+": "+", "alt++": "Alt++", "ctrl+alt+x": "Ctrl+Alt+x",
+		"alt+": "Alt+", "": "", "ctrl++a": "Ctrl++a",
+	} {
+		if v := keyHelp(k); v != expected {
+			t.Errorf("expected help %q of key %q, got: %q", expected, k, v)
+		}
+	}
+	if _, warns := NewKeyMap(map[string][]string{"Help": {"+"}, "Filter": {"alt++"}}); len(warns) > 0 {
+		t.Errorf("expected no warnings, got: %v", warns)
+	}
+}
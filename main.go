@@ -2,12 +2,15 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"flag"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
+	"net/http/httptrace"
 	"net/textproto"
 	"net/url"
 	"os"
@@ -20,7 +23,7 @@ import (
 	"github.com/alecthomas/chroma/v2"
 	"github.com/alecthomas/chroma/v2/formatters"
 	"github.com/alecthomas/chroma/v2/lexers"
-	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textarea"
 	"github.com/charmbracelet/bubbles/textinput"
@@ -50,6 +53,18 @@ const (
 	form
 	formVal
 
+	// Auth settings: type and name of API key (token URL or region), secrets (user and
+	// password, token, client credentials or AWS keys), scopes and audience of OAuth2
+	// (service and session token of AWS).
+	auth
+	authVal
+
+	secret
+	secretVal
+
+	scope
+	scopeVal
+
 	// The last one is the max index of defined text input,
 	// this is abroad between text inputs and checkboxes.
 	fieldsCount
@@ -59,7 +74,12 @@ const (
 	// Index of checkbox can be calculated in this way:
 	//   m.checkboxes[i - fieldsCount - 1]
 	https
+	insecure
 	autoformat
+	multipartForm
+	validateBody
+	noRedirects
+	cookieJar
 
 	// last index
 	end
@@ -70,14 +90,38 @@ const (
 	sessionSave = end + iota + 1
 	sessionLoad
 	payload
+	export
 
 	fileInputsEnd
 )
 
+// Prompts.
+const (
+	curlImport = fileInputsEnd + iota + 1
+	collectionRename
+	customContentType
+	proxyOverride
+	tlsOverride
+	cookieEdit
+	bodySearch
+	jsonFilter
+
+	promptsEnd
+)
+
 // Right panel content.
 const (
-	helpView = fileInputsEnd + iota + 1
+	helpView = promptsEnd + iota + 1
 	jsonEditView
+	historyView
+	collectionView
+	signatureView
+	connView
+	redirectView
+	jarView
+
+	// JSON tree of response, it is shown in place of response body.
+	treeView
 )
 
 // Request payload types.
@@ -86,12 +130,16 @@ const (
 	jsonPayload
 	formPayload
 	file
+	multipartPayload
+	rawPayload
 )
 
 var (
-	showHelp, printDefaultConf bool
-	configPath, chromaStyle    string
-	timeout, maxRedirects      int
+	showHelp, printDefaultConf, checkConfig bool
+	formArgs                                bool // send fields of command line as form
+	configPath, chromaStyle                 string
+	sessionPath                             string
+	timeout, maxRedirects                   int
 
 	screenWidth  = 100
 	screenHeight = 50
@@ -109,7 +157,11 @@ var (
 
 func init() {
 	flag.StringVar(&configPath, "c", "", "config file")
+	flag.StringVar(&sessionPath, "s", "", "open session file")
+	flag.BoolVar(&formArgs, "f", false, "send fields of command line as form, they are sent as JSON by default")
+	flag.BoolVar(&formArgs, "form", false, "send fields of command line as form, they are sent as JSON by default")
 	flag.BoolVar(&printDefaultConf, "print-default-config", false, "print default config and exit")
+	flag.BoolVar(&checkConfig, "check-config", false, "print config warnings and exit, exit code is 1 if there are any")
 	flag.BoolVar(&showHelp, "h", false, "show help")
 	flag.BoolVar(&showHelp, "help", false, "show help")
 }
@@ -122,6 +174,14 @@ func fileinputIndex(i int) (idx int) {
 	return
 }
 
+func promptIndex(i int) (idx int) {
+	idx = i - fileInputsEnd - 1
+	if idx < 0 {
+		idx = 0 // first prompt
+	}
+	return
+}
+
 func checkboxIndex(i int) (idx int) {
 	idx = i - fieldsCount - 1
 	if idx < 0 {
@@ -135,36 +195,94 @@ func newReqest() (r *http.Request) {
 	return
 }
 
-// Prepare request before send.
-// TODO consider refactoring/remove all this function to avoid using of global variables
-func prepareRequest(r *http.Request, p int) {
-	switch p {
-	// case file: ? (see todo)
+// State of request to send: it's taken from the global variables on the UI goroutine,
+// so the request goroutine does not race with edits of UI.
+type sendState struct {
+	req              *http.Request
+	payload          int
+	form             map[string][]string
+	json, raw, rawCT string
+	auth             Auth
+	jar              http.CookieJar // nil: cookies are not sent
+}
+
+// Take state of request to send, placeholders of active environment are expanded.
+func newSendState(r *http.Request, p int) sendState {
+	s := sendState{
+		req: expandRequest(r), payload: p, form: expandValues(formValues),
+		json: expandVars(jsonPayloadEncoded), raw: expandVars(rawPayloadEncoded), rawCT: rawPayloadContentType,
+		auth: expandAuth(reqAuth),
+	}
+	if useCookieJar {
+		s.jar = jar.For(activeEnv)
+	}
+	return s
+}
+
+// Prepare request before send: the body of payload is set.
+func prepareRequest(r *http.Request, s sendState) error {
+	switch s.payload {
 	case formPayload:
 		sbar.Info("send form values")
 		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
-		r.Body = io.NopCloser(strings.NewReader(formValues.Encode()))
+		r.Body = io.NopCloser(strings.NewReader(url.Values(s.form).Encode()))
 	case jsonPayload:
 		sbar.Info("send JSON payload")
 		r.Header.Set("Content-Type", "application/json")
-		r.Body = io.NopCloser(strings.NewReader(jsonPayloadEncoded))
-	}
-}
-
-var redirects []string
-
-func handleRedirect(req *http.Request, via []*http.Request) error {
-	redirects = append(redirects, strconv.Itoa(req.Response.StatusCode)+" "+req.URL.String())
-	if len(redirects) > maxRedirects {
-		return errors.New("max redirects (" + strconv.Itoa(maxRedirects) + ") followed")
+		r.Body = io.NopCloser(strings.NewReader(s.json))
+	case rawPayload:
+		sbar.Info("send " + s.rawCT + " payload")
+		r.Header.Set("Content-Type", s.rawCT)
+		r.Body = io.NopCloser(strings.NewReader(s.raw))
+	case multipartPayload:
+		sbar.Info("send multipart form")
+		body, ct, err := encodeMultipart(s.form)
+		if err != nil {
+			return err
+		}
+		r.Header.Set("Content-Type", ct)
+		r.ContentLength = int64(body.Len())
+		r.Body = io.NopCloser(body)
 	}
 	return nil
 }
 
-func sendRequest(r *http.Request, p int) (*http.Response, error) {
+func sendRequest(s sendState, t *http.Transport) (*http.Response, error) {
 	redirects = nil
-	http_cli := http.Client{Timeout: time.Duration(timeout) * time.Second, CheckRedirect: handleRedirect}
-	prepareRequest(r, p)
+	http_cli := http.Client{
+		Timeout: time.Duration(timeout) * time.Second, CheckRedirect: handleRedirect, Transport: t, Jar: s.jar}
+	r := s.req
+	if err := prepareRequest(r, s); err != nil {
+		return nil, err
+	}
+	switch s.auth.Type {
+	case authDigest:
+		return doDigestAuth(&http_cli, r, s.auth)
+	case authOAuth2:
+		t, err := oauth2Token(r.Context(), &http_cli, s.auth)
+		if err != nil {
+			return nil, err
+		}
+		r.Header.Set("Authorization", "Bearer "+t.AccessToken)
+	case authSigV4:
+		canonicalRequest, stringToSign, err := signSigV4(r, s.auth, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		setSignatureDebug(canonicalRequest, stringToSign)
+	}
+	applyAuth(r, s.auth)
+	if s.auth.Type == authHMAC {
+		signer, ok := signers[s.auth.Name]
+		if !ok {
+			return nil, errors.New("signer " + s.auth.Name + " not found in config")
+		}
+		stringToSign, err := signHMAC(r, s.auth, signer, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		setSignatureDebug("", stringToSign)
+	}
 	return http_cli.Do(r)
 }
 
@@ -211,6 +329,9 @@ type model struct {
 	inputs       []textinput.Model
 	checkboxes   []Checkbox
 	fileInputs   []FileInput
+	prompts      []Prompt
+	history      History
+	collection   Collection
 	textArea     textarea.Model
 	cursorIdx    int    // edit type
 	cursorKey    string // edit key of type orderedKeyVal store
@@ -221,6 +342,21 @@ type model struct {
 	rpView       int // right panel view: help or textarea
 	reqPayload   int
 	pressedKey   string
+	exportFormat int                // export format: curl, HTTPie or Go
+	bodyType     int                // type of body of editor: JSON, XML etc
+	customCT     string             // content type of custom body type
+	editorTop    int                // first visible line of body editor
+	reqId        int                // id of the last sent request
+	cancel       context.CancelFunc // cancel of in-flight request
+	trace        *Trace             // trace of the last sent request
+	timing       *Timing            // timing of the last response
+	redirects    []RedirectHop      // redirect chain of the last response
+	stepHops     []RedirectHop      // redirect chain followed by hand so far
+	search       BodySearch         // search in response body
+	resBody      string             // body of response as is
+	filter       string             // filter of JSON response
+	treeMode     bool               // JSON response is shown as tree
+	tree         *JSONTree          // tree of JSON response, nil if response is not JSON
 	KeyStroke
 }
 
@@ -235,13 +371,31 @@ func (m *model) blurAllPrompts() {
 	for i := range m.fileInputs {
 		m.fileInputs[i].Hide()
 	}
+	for i := range m.prompts {
+		m.prompts[i].Hide()
+	}
+}
+
+// Show or hide the prompt of right panel.
+func (m *model) togglePrompt(id int) {
+	idx := promptIndex(id)
+	if !m.prompts[idx].IsVisible() {
+		m.blurAllPrompts()
+		m.prompts[idx].SetVisible()
+		m.prompts[idx].Focus()
+		m.focused = id
+	} else {
+		m.blurAllPrompts()
+		m.focused = 0
+		m.focusPrompt(0)
+	}
 }
 
 // Blur prompt.
 func (m *model) blurPrompt(i int) {
 	p := i
 	switch i {
-	case headerVal, paramVal, cookieVal, formVal:
+	case headerVal, paramVal, cookieVal, formVal, authVal, secretVal, scopeVal:
 		p = i - 1
 	}
 	if i < fieldsCount {
@@ -258,7 +412,7 @@ func (m *model) blurPrompt(i int) {
 func (m *model) focusPrompt(i int) {
 	n := i
 	switch i {
-	case headerVal, paramVal, cookieVal, formVal:
+	case headerVal, paramVal, cookieVal, formVal, authVal, secretVal, scopeVal:
 		n = i - 1
 	}
 	if i < fieldsCount {
@@ -273,8 +427,21 @@ func (m *model) focusPrompt(i int) {
 
 }
 
+// Focus the first input field, e.g. after focused view (history, JSON tree etc).
+func (m *model) focusFirstInput() {
+	m.blurAllPrompts()
+	m.textArea.Blur()
+	m.history.Blur()
+	m.focused = 0
+	m.focusPrompt(0)
+}
+
 // nextInput focuses the next input field
 func (m *model) nextInput() {
+	if m.focused >= end {
+		m.focusFirstInput()
+		return
+	}
 	switch m.focused {
 	case method:
 		m.setReqMethod()
@@ -291,6 +458,10 @@ func (m *model) nextInput() {
 
 // prevInput focuses the previous input field
 func (m *model) prevInput() {
+	if m.focused >= end {
+		m.focusFirstInput()
+		return
+	}
 
 	m.blurPrompt(m.focused)
 
@@ -312,11 +483,29 @@ func (m *model) reqIsExecuted() bool {
 	return m.res != nil
 }
 
+// Request is in flight.
+func (m *model) reqIsInFlight() bool {
+	return m.cancel != nil
+}
+
+// Cancel in-flight request (if any) and release its context.
+func (m *model) cancelReq() {
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
+}
+
 // Clear response artefacts.
 func (m *model) clearRespArtefacts() {
 	m.res = nil
+	m.timing = nil
+	m.redirects = nil
 	m.resBodyLines = nil
+	m.resBody = ""
+	m.tree = nil
 	m.offset = 0
+	m.search.Find(m.search.query, nil)
 }
 
 // Get page of response.
@@ -419,10 +608,75 @@ func (m *model) delReqForm() {
 	name := m.inputs[form].Value()
 	if name != "" {
 		formValues.Del(name)
-		if len(formValues) == 0 {
+		m.setFormPayload()
+	}
+}
+
+// Restore payload of saved request: form values, JSON, raw body or file, the body
+// editor is filled by JSON or raw body.
+func (m *model) setPayload(r Request) {
+	m.reqPayload = nothing
+	switch p := payloadType(r.Payload); p {
+	case jsonPayload:
+		jsonPayloadEncoded = r.Body
+		m.bodyType = bodyJSON
+		m.textArea.SetValue(autoFormatJSON(r.Body))
+		m.reqPayload = p
+	case rawPayload:
+		ct := m.req.Header.Get("Content-Type")
+		rawPayloadEncoded, rawPayloadContentType = r.Body, ct
+		m.bodyType, m.customCT = bodyCustom, ct
+		for i, bt := range bodyTypes {
+			if bt.ContentType == ct {
+				m.bodyType = i
+				break
+			}
+		}
+		m.textArea.SetValue(r.Body)
+		m.reqPayload = p
+	case file:
+		f, err := os.Open(r.Body)
+		if err != nil {
+			sbar.Error("cannot open payload: " + err.Error())
+			return
+		}
+		filePayload = r.Body
+		m.req.Body = f
+		m.reqPayload = p
+	case formPayload, multipartPayload:
+		if p == multipartPayload {
+			m.checkboxes[checkboxIndex(multipartForm)].SetOn()
+		} else {
+			m.checkboxes[checkboxIndex(multipartForm)].SetOff()
+		}
+		fallthrough
+	default:
+		m.setFormPayload()
+	}
+	m.textArea.Placeholder = bodyTypes[m.bodyType].Placeholder
+}
+
+// Set type of form payload: multipart if it's turned on or there is a file part,
+// otherwise urlencoded form.
+func (m *model) setFormPayload() {
+	idx := checkboxIndex(multipartForm)
+	switch {
+	case len(formValues) == 0:
+		if m.reqPayload == formPayload || m.reqPayload == multipartPayload {
 			m.reqPayload = nothing
 			m.req.Header.Del("Content-Type")
 		}
+		return
+	case hasFileParts(formValues):
+		m.checkboxes[idx].SetOn()
+	}
+
+	if m.checkboxes[idx].IsOn() {
+		m.req.Header.Set("Content-Type", "multipart/form-data")
+		m.reqPayload = multipartPayload
+	} else {
+		m.req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		m.reqPayload = formPayload
 	}
 }
 
@@ -442,10 +696,9 @@ func (m *model) setReqForm() {
 		}
 		m.inputs[form].SetSuggestions(s1)
 		m.inputs[formVal].SetSuggestions(s2)
-		m.req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
 		m.inputs[method].SetValue("POST")
 		m.req.Method = "POST"
-		m.reqPayload = formPayload
+		m.setFormPayload()
 		m.inputs[form].Reset()
 		m.inputs[formVal].Reset()
 	}
@@ -477,6 +730,119 @@ func (m *model) setReqHost() {
 	m.req.Host = val
 }
 
+// Set auth settings from auth inputs, the type of auth is autocompleted.
+func (m *model) setReqAuth() {
+	t := strings.ToLower(m.inputs[auth].Value())
+	idx := slices.IndexFunc(authTypes, func(a string) bool { return strings.ToLower(a) == t })
+	if idx < 0 && t != "" {
+		idx = slices.IndexFunc(authTypes, func(a string) bool {
+			return strings.HasPrefix(strings.ToLower(a), t)
+		})
+	}
+	if idx < 0 {
+		sbar.Error("unknown auth type: " + m.inputs[auth].Value() + ", allowed: " + strings.Join(authTypes, ", "))
+		m.setAuthInputs()
+		return
+	}
+
+	reqAuth = Auth{Type: authTypes[idx]}
+	switch reqAuth.Type {
+	case authBasic, authDigest:
+		reqAuth.User = m.inputs[secret].Value()
+		reqAuth.Password = m.inputs[secretVal].Value()
+	case authBearer:
+		reqAuth.Token = m.inputs[secret].Value()
+	case authAPIKey, authAPIKeyQuery:
+		reqAuth.Name = m.inputs[authVal].Value()
+		if reqAuth.Name == "" {
+			reqAuth.Name = m.inputs[authVal].Placeholder
+		}
+		reqAuth.Token = m.inputs[secret].Value()
+	case authOAuth2:
+		reqAuth.TokenURL = m.inputs[authVal].Value()
+		reqAuth.ClientID = m.inputs[secret].Value()
+		reqAuth.ClientSecret = m.inputs[secretVal].Value()
+		reqAuth.Scopes = m.inputs[scope].Value()
+		reqAuth.Audience = m.inputs[scopeVal].Value()
+	case authSigV4:
+		reqAuth.Region = m.inputs[authVal].Value()
+		reqAuth.User = m.inputs[secret].Value()
+		reqAuth.Password = m.inputs[secretVal].Value()
+		reqAuth.Service = m.inputs[scope].Value()
+		reqAuth.Token = m.inputs[scopeVal].Value()
+	case authHMAC:
+		reqAuth.Name = m.inputs[authVal].Value()
+		if reqAuth.Name == "" {
+			reqAuth.Name = m.inputs[authVal].Placeholder
+		}
+		reqAuth.User = m.inputs[secret].Value()
+		reqAuth.Password = m.inputs[secretVal].Value()
+	}
+	m.setAuthInputs()
+	sbar.Info("auth: " + reqAuth.String())
+}
+
+// Update auth inputs according to auth settings of request.
+func (m *model) setAuthInputs() {
+	var name, user, pass, scopes, audience string
+	switch reqAuth.Type {
+	case authBasic, authDigest:
+		user, pass = "user", "password"
+	case authOAuth2:
+		name, user, pass, scopes, audience = "token URL", "client id", "client secret", "scopes", "audience"
+	case authSigV4:
+		name, user, pass, scopes, audience = "region", "access key", "secret key", "service", "session token"
+	case authHMAC:
+		name, user, pass = "signer", "key id", "key"
+		if names := signerNames(); len(names) > 0 {
+			name = names[0]
+		}
+	case authBearer:
+		user = "token"
+	case authAPIKey:
+		name, user = "X-Api-Key", "key"
+	case authAPIKeyQuery:
+		name, user = "api_key", "key"
+	}
+	if reqAuth.Type == "" {
+		reqAuth.Type = authNone
+	}
+	m.inputs[auth].SetValue(reqAuth.Type)
+	m.inputs[authVal].Placeholder = name
+	m.inputs[authVal].SetValue(reqAuth.Name)
+	m.inputs[secret].Placeholder = user
+	m.inputs[secretVal].Placeholder = pass
+	m.inputs[secret].EchoMode = textinput.EchoNormal
+	if reqAuth.Type == authBearer || reqAuth.Type == authAPIKey || reqAuth.Type == authAPIKeyQuery {
+		m.inputs[secret].EchoMode = textinput.EchoPassword // token is a secret
+	}
+	m.inputs[scope].Placeholder = scopes
+	m.inputs[scopeVal].Placeholder = audience
+	m.inputs[scope].SetValue(reqAuth.Scopes)
+	m.inputs[scopeVal].SetValue(reqAuth.Audience)
+	switch reqAuth.Type {
+	case authBasic, authDigest:
+		m.inputs[secret].SetValue(reqAuth.User)
+		m.inputs[secretVal].SetValue(reqAuth.Password)
+	case authOAuth2:
+		m.inputs[authVal].SetValue(reqAuth.TokenURL)
+		m.inputs[secret].SetValue(reqAuth.ClientID)
+		m.inputs[secretVal].SetValue(reqAuth.ClientSecret)
+	case authSigV4:
+		m.inputs[authVal].SetValue(reqAuth.Region)
+		m.inputs[secret].SetValue(reqAuth.User)
+		m.inputs[secretVal].SetValue(reqAuth.Password)
+		m.inputs[scope].SetValue(reqAuth.Service)
+		m.inputs[scopeVal].SetValue(reqAuth.Token)
+	case authHMAC:
+		m.inputs[secret].SetValue(reqAuth.User)
+		m.inputs[secretVal].SetValue(reqAuth.Password)
+	default:
+		m.inputs[secret].SetValue(reqAuth.Token)
+		m.inputs[secretVal].Reset()
+	}
+}
+
 func (m *model) restoreReqMethod() {
 	m.inputs[method].SetValue(m.req.Method)
 	m.inputs[method].CursorEnd()
@@ -513,6 +879,46 @@ func (m *model) setReqJsonPayload() {
 	}
 }
 
+// Set request body of editor according to selected body type,
+// JSON payload is compacted when validation is turned on.
+func (m *model) setReqBody() {
+	bt := bodyTypes[m.bodyType]
+	payload := m.textArea.Value()
+	validate := m.checkboxes[checkboxIndex(validateBody)].IsOn()
+	if m.bodyType == bodyJSON && validate {
+		m.setReqJsonPayload()
+		return
+	}
+	if validate && bt.Validate != nil {
+		if err := bt.Validate(payload); err != nil {
+			sbar.Error(err.Error())
+			return
+		}
+	}
+
+	ct := bt.ContentType
+	if m.bodyType == bodyCustom {
+		ct = m.customCT
+	}
+	if ct == "" {
+		sbar.Error("content type of body is not set")
+		return
+	}
+	m.req.Header.Set("Content-Type", ct)
+	m.req.Method = "POST"
+	m.inputs[method].SetValue("POST")
+	m.reqPayload = rawPayload
+	rawPayloadEncoded = payload
+	rawPayloadContentType = ct
+	sbar.Info(bt.Name + " payload is updated")
+}
+
+// Switch body type of editor.
+func (m *model) nextBodyType() {
+	m.bodyType = (m.bodyType + 1) % bodyTypesEnd
+	m.textArea.Placeholder = bodyTypes[m.bodyType].Placeholder
+}
+
 func headerValidator(s string) error {
 	// TODO add header validation
 	// https://developers.cloudflare.com/rules/transform/request-header-modification/reference/header-format/
@@ -534,11 +940,11 @@ var allowedMethods = []string{
 
 var prompts = [fieldsCount]string{
 	"Method ", "Host ", "Path   ",
-	"Header ", "", "Param  ", "", "Cookie ", "", "Form   ", ""}
+	"Header ", "", "Param  ", "", "Cookie ", "", "Form   ", "", "Auth   ", "", "Secret ", "", "Scope  ", ""}
 var placeholders = [fieldsCount]string{
 	"GET", "example.com", "/",
 	"X-Auth-Token", "token value", "products_id", "10",
-	"XDEBUG_SESSION", "debugger", "login", "user"}
+	"XDEBUG_SESSION", "debugger", "login", "user", "None", "", "", "", "", ""}
 
 func NewKeyValInputs(n int) textinput.Model {
 	t := textinput.New()
@@ -561,21 +967,35 @@ func NewKeyValInputs(n int) textinput.Model {
 		t.Focus() // start program with first prompt activated
 	case urlPath:
 		t.Width = 52
+	case auth:
+		t.SetSuggestions(authTypes)
+	case secretVal:
+		t.EchoMode = textinput.EchoPassword
 	}
 	return t
 }
 
+// Apply settings of config used by sending of requests.
+func applySettings(conf *Config) {
+	timeout = conf.Timeout
+	maxRedirects = conf.MaxRedirects
+	chromaStyle = conf.Chroma
+	signers = conf.Signers
+	proxySettings = conf.Proxy
+	tlsSettings = conf.TLS
+}
+
 func initialModel(conf *Config) model {
 	var inputs []textinput.Model
 	var checkboxes []Checkbox
 	var fileInputs []FileInput
+	var prompts []Prompt
 
 	req := newReqest()
 
+	applySettings(conf)
+
 	// update styles according to theme colors
-	timeout = conf.Timeout
-	maxRedirects = conf.MaxRedirects
-	chromaStyle = conf.Chroma
 	baseStyle := lipgloss.NewStyle().Width(screenWidth)
 	promptStyle = lipgloss.NewStyle().Foreground(conf.Color("textinputPrompt")).Bold(true)
 	promptActiveStyle = lipgloss.NewStyle().Foreground(conf.Color("textinputPromptActive")).Bold(true)
@@ -597,12 +1017,24 @@ func initialModel(conf *Config) model {
 
 	headerNameStyle = lipgloss.NewStyle().Foreground(conf.Color("headerName"))
 	headerValueStyle = lipgloss.NewStyle().Foreground(conf.Color("headerValue"))
+	connWarningStyle = lipgloss.NewStyle().Foreground(conf.Color("connWarning"))
+
+	for i, c := range []string{
+		"jsonTreeKey", "jsonTreeString", "jsonTreeNumber", "jsonTreeLiteral", "jsonTreeSummary", "jsonTreeCursor",
+	} {
+		treeStyles[i] = lipgloss.NewStyle().Foreground(conf.Color(c))
+	}
+	treeStyles[5] = treeStyles[5].Bold(true)
 
 	urlStyle = lipgloss.NewStyle().Inherit(baseStyle).
 		Foreground(conf.Color("url")).
 		Bold(true).Padding(0, 1)
 
 	sbar = NewStatusBar(conf)
+	if !setEnvironments(conf.Environments, conf.Environment) {
+		conf.AddWarn(`environment "` + conf.Environment + `" not found`)
+	}
+	sbar.SetEnvironment(activeEnv)
 
 	w, h, err := term.GetSize(int(os.Stdout.Fd()))
 	if err != nil {
@@ -620,11 +1052,33 @@ func initialModel(conf *Config) model {
 		req.URL.Scheme = "https"
 		c1.SetOn()
 	}
+	c5 := NewCheckbox(insecure, "insecure ", "⟨on⟩ ", "⟨off⟩", promptStyle, checkboxOnStyle, checkboxOffStyle)
+	if conf.Checkboxes["insecure"] {
+		c5.SetOn()
+	}
 	c2 := NewCheckbox(autoformat, "Auto format JSON ", "⟨on⟩ ", "⟨off⟩", promptStyle, checkboxOnStyle, checkboxOffStyle)
 	if conf.Checkboxes["autoformat"] {
 		c2.SetOn()
 	}
-	checkboxes = append(checkboxes, c1, c2)
+	c3 := NewCheckbox(multipartForm, "multipart ", "⟨on⟩ ", "⟨off⟩", promptStyle, checkboxOnStyle, checkboxOffStyle)
+	if conf.Checkboxes["multipart"] {
+		c3.SetOn()
+	}
+	c4 := NewCheckbox(validateBody, "Validate body    ", "⟨on⟩ ", "⟨off⟩", promptStyle, checkboxOnStyle, checkboxOffStyle)
+	if conf.Checkboxes["validate"] {
+		c4.SetOn()
+	}
+	c6 := NewCheckbox(noRedirects, "no redirects ", "⟨on⟩ ", "⟨off⟩", promptStyle, checkboxOnStyle, checkboxOffStyle)
+	if conf.Checkboxes["noRedirects"] {
+		c6.SetOn()
+		skipRedirects = true
+	}
+	c7 := NewCheckbox(cookieJar, "cookie jar ", "⟨on⟩ ", "⟨off⟩", promptStyle, checkboxOnStyle, checkboxOffStyle)
+	if conf.Checkboxes["cookieJar"] {
+		c7.SetOn()
+		useCookieJar = true
+	}
+	checkboxes = append(checkboxes, c1, c5, c2, c3, c4, c6, c7)
 
 	fiColors := []lipgloss.Color{
 		conf.Color("fileinputPrompt"),
@@ -634,8 +1088,21 @@ func initialModel(conf *Config) model {
 	f1 := NewFileInput(sessionSave, WriteMode, "Session save: ", "/home/user/ses.json", fiColors...)
 	f2 := NewFileInput(sessionLoad, ReadMode, "Session load: ", "/home/user/ses.json", fiColors...)
 	f3 := NewFileInput(payload, ReadMode, "Payload: ", "/home/user/data.json", fiColors...)
+	f4 := NewFileInput(export, WriteMode, "Export: ", "empty for clipboard", fiColors...)
+
+	fileInputs = append(fileInputs, f1, f2, f3, f4)
+
+	p1 := NewPrompt(curlImport, "curl: ", "curl -X POST https://example.com -d ...", fiColors...)
 
-	fileInputs = append(fileInputs, f1, f2, f3)
+	p2 := NewPrompt(collectionRename, "Rename: ", "new name", fiColors...)
+	p3 := NewPrompt(customContentType, "Content-Type: ", "application/vnd.api+json", fiColors...)
+	p4 := NewPrompt(proxyOverride, "Proxy: ", "socks5://127.0.0.1:1080, direct or empty for settings", fiColors...)
+	p5 := NewPrompt(tlsOverride, "TLS: ", "ca=ca.pem cert=c.pem key=k.pem min=1.2 max=1.3 sni=name", fiColors...)
+	p6 := NewPrompt(cookieEdit, "Cookie: ", "sid=abc; Path=/; Domain=example.com; Max-Age=3600; Secure", fiColors...)
+	p7 := NewPrompt(bodySearch, (&BodySearch{}).Title(), "text of response body", fiColors...)
+	p8 := NewPrompt(jsonFilter, "Filter: ", ".items[].id | $.data[0].name | .headers | keys", fiColors...)
+
+	prompts = append(prompts, p1, p2, p3, p4, p5, p6, p7, p8)
 
 	txt := textarea.New()
 	txt.MaxHeight = 0
@@ -647,14 +1114,42 @@ func initialModel(conf *Config) model {
 		Placeholder: textAreaPlaceholder,
 	}
 
+	hist := NewHistory(expandHome(conf.History), conf.HistorySize, append(fiColors,
+		conf.Color("historyItem"), conf.Color("historyItemActive"))...)
+	if n, err := hist.Load(); err != nil {
+		sbar.Error("cannot load history: " + err.Error())
+	} else if n > 0 {
+		conf.AddWarn("history: " + strconv.Itoa(n) + " malformed entries are skipped")
+	}
+
+	coll := NewCollection(expandHome(conf.Collection), conf.Color("historyItem"),
+		conf.Color("historyItemActive"), conf.Color("collectionFolder"))
+	coll.saveKey = conf.KeyMap.SaveToCollection.Help().Key
+	if err := coll.Load(); err != nil {
+		sbar.Error("cannot load collection: " + err.Error())
+	}
+
+	jar = NewCookieJar(expandHome(conf.CookieJar), conf.Color("historyItem"), conf.Color("historyItemActive"))
+	if err := jar.Load(); err != nil {
+		sbar.Error("cannot load cookie jar: " + err.Error())
+	}
+
 	m := model{
 		req:        req,
 		inputs:     inputs,
 		checkboxes: checkboxes,
 		fileInputs: fileInputs,
+		prompts:    prompts,
+		history:    hist,
+		collection: coll,
 		textArea:   txt,
 		rpView:     helpView,
-		KeyStroke:  NewKeyStroke(conf.Color("helpKey"), conf.Color("helpDesc")),
+		KeyStroke:  NewKeyStroke(conf.KeyMap, conf.Color("helpKey"), conf.Color("helpDesc")),
+	}
+	m.setProxyIndicator()
+	m.transport() // errors of TLS settings are shown at start
+	if conf.HasWarnings() {
+		sbar.Warning("config: " + conf.WarningMessage())
 	}
 	return m
 }
@@ -690,19 +1185,25 @@ func autoFormatJSON(s string) string {
 	return out.String()
 }
 
-func formatRespBody(ct, s string, autoformat bool) []string {
-	var content strings.Builder
-
-	if s == "" {
-		return []string{}
-	}
-
+// Lexer of content: match it by content type or detect it by content.
+func lexerOf(ct, s string) chroma.Lexer {
 	lexer := matchContentTypeTolexer(ct)
 	if lexer == nil {
 		// detect lang
 		lexer = lexers.Analyse(s)
 	}
-	lexer = chroma.Coalesce(lexer)
+	if lexer == nil {
+		lexer = lexers.Fallback
+	}
+	return chroma.Coalesce(lexer)
+}
+
+func formatRespBody(ct, s string, autoformat bool) []string {
+	if s == "" {
+		return []string{}
+	}
+
+	lexer := lexerOf(ct, s)
 
 	if autoformat && lexer.Config().Name == "JSON" {
 		s = autoFormatJSON(s)
@@ -712,11 +1213,15 @@ func formatRespBody(ct, s string, autoformat bool) []string {
 	lp := lipgloss.NewStyle().Width(screenWidth).Padding(0, 1)
 	s = lp.Render(s)
 
+	return strings.Split(highlight(lexer, s), "\n")
+}
+
+// Highlight text with chroma style of theme.
+func highlight(lexer chroma.Lexer, s string) string {
+	var content strings.Builder
+
 	// pick a style
-	style := styles.Get(chromaStyle)
-	if style == nil {
-		style = styles.Fallback
-	}
+	style := themeChromaStyle()
 
 	// pick a formatter
 	formatter := formatters.Get("terminal16m")
@@ -732,18 +1237,20 @@ func formatRespBody(ct, s string, autoformat bool) []string {
 		panic(err)
 	}
 
-	return strings.Split(content.String(), "\n")
+	return content.String()
 }
 
-// Timer is a data container for some payload + time started.
+// Timer is a data container for some payload + time started,
+// id is the id of request which the payload belongs to.
 type Timer struct {
+	id      int
 	start   time.Time
 	payload tea.Msg
 }
 
 // New message with timer.
-func NewMessageWithTimer(payload any) Timer {
-	return Timer{time.Now(), payload}
+func NewMessageWithTimer(id int, start time.Time, payload any) Timer {
+	return Timer{id, start, payload}
 }
 
 // Elapsed time from start of timer.
@@ -759,6 +1266,174 @@ func (m *model) checkboxHandler(msg tea.Msg, i int) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+// Set request from the given [Request] data: create a new request instance,
+// update inputs and checkboxes.
+func (m *model) setRequest(r Request) {
+	// the in-flight request is not needed anymore
+	m.cancelReq()
+	m.reqId++
+
+	formValues = cloneValues(r.FormValues) // edits do not change the saved request
+	if formValues == nil {
+		formValues = make(url.Values)
+	}
+
+	// Create a new request instance
+	m.req = r.NewHTTPRequest()
+
+	// req scheme (http or https)
+	idx := checkboxIndex(https)
+	if m.req.URL.Scheme == "https" {
+		m.checkboxes[idx].SetOn()
+	} else {
+		m.checkboxes[idx].SetOff()
+	}
+
+	m.inputs[method].SetValue(r.Method)
+	m.inputs[host].SetValue(r.Host)
+	m.inputs[urlPath].SetValue(r.UrlPath)
+
+	// req auth settings
+	reqAuth = Auth{}
+	if r.Auth != nil {
+		reqAuth = *r.Auth
+	}
+	m.setAuthInputs()
+
+	// req proxy override
+	reqProxy = r.Proxy
+	m.setProxyIndicator()
+
+	// req TLS override
+	reqTLS = TLS{}
+	if r.TLS != nil {
+		reqTLS = *r.TLS
+	}
+	idx = checkboxIndex(insecure)
+	if r.Insecure {
+		m.checkboxes[idx].SetOn()
+	} else {
+		m.checkboxes[idx].SetOff()
+	}
+	m.transport() // errors of TLS override are shown on load
+}
+
+// Transport of request according to TLS settings, TLS override of request, insecure
+// checkbox and proxy of request, errors are shown in the status bar.
+func (m *model) transport() (*http.Transport, bool) {
+	t, err := transportOf(tlsSettings.Merge(reqTLS), m.checkboxes[checkboxIndex(insecure)].IsOn(),
+		expandVars(reqProxy))
+	if err != nil {
+		sbar.Error("TLS: " + err.Error())
+		return nil, false
+	}
+	return t, true
+}
+
+// Show proxy of current request in the status bar.
+func (m *model) setProxyIndicator() {
+	p, err := proxyFunc(proxySettings, expandVars(reqProxy))(expandRequest(m.req))
+	if err != nil || p == nil {
+		sbar.SetProxy("")
+		return
+	}
+	sbar.SetProxy(p.Scheme + "://" + p.Host)
+}
+
+// Send request, the in-flight request is superseded by the new one.
+func (m *model) runRequest() tea.Cmd {
+	t, ok := m.transport()
+	if !ok {
+		return nil
+	}
+	sbar.Info("sending request...")
+	m.cancelReq() // new request supersedes in-flight one
+	m.clearRespArtefacts()
+	sbar.IncReqCount()
+	var ctx context.Context
+	ctx, m.cancel = context.WithCancel(context.Background())
+	m.trace = &Trace{}
+	m.req = m.req.WithContext(httptrace.WithClientTrace(ctx, m.trace.ClientTrace()))
+	m.reqId++
+	id, s := m.reqId, newSendState(m.req, m.reqPayload)
+	return func() tea.Msg {
+		start := time.Now()
+		r, err := sendRequest(s, t)
+		if err != nil {
+			return NewMessageWithTimer(id, start, err)
+		}
+		return NewMessageWithTimer(id, start, r)
+	}
+}
+
+// Follow the redirect of response by hand: the request is updated to the next URL
+// and sent, the redirect chain is kept.
+func (m *model) followRedirect() tea.Cmd {
+	u := redirectLocation(m.res)
+	if u == nil {
+		sbar.Warning("response is not a redirect")
+		return nil
+	}
+	hops := append(slices.Clone(m.redirects), newRedirectHop(m.res.Request, m.res, u))
+
+	ses := m.newSession(false)
+	ses.Request.Scheme = u.Scheme
+	ses.Request.Host = u.Host
+	ses.Request.UrlPath = u.Path
+	ses.Request.RawQuery = u.RawQuery
+	ses.Request.Method = redirectMethod(m.res.StatusCode, m.req.Method)
+	if initial, err := url.Parse(hops[0].URL); err == nil && dropRedirectCredentials(&ses.Request, initial, u) {
+		sbar.Warning("auth and cookies are not sent to another host: " + u.Hostname())
+	}
+	m.setRequest(ses.Request)
+	if ses.Request.Method != m.res.Request.Method {
+		m.reqPayload = nothing // the body is dropped with method
+		m.req.Header.Del("Content-Type")
+	}
+
+	m.stepHops = hops
+	return m.runRequest()
+}
+
+// Create a new session of current state, response is optional.
+func (m *model) newSession(withResponse bool) *Session {
+	var ses *Session
+	if withResponse {
+		ses, _ = NewSession(
+			m.req, m.res, sbar.GetReqCount(),
+			sbar.GetResTime(), formValues, m.resBodyLines)
+	} else {
+		ses, _ = NewSession(m.req, nil, 0, "", formValues, nil)
+	}
+	if reqAuth.IsSet() {
+		a := reqAuth
+		ses.Request.Auth = &a
+	}
+	ses.Request.Proxy = reqProxy
+	if !reqTLS.IsEmpty() {
+		t := reqTLS
+		ses.Request.TLS = &t
+	}
+	ses.Request.Insecure = m.checkboxes[checkboxIndex(insecure)].IsOn()
+	ses.Request.Payload = payloadNames[m.reqPayload]
+	switch m.reqPayload {
+	case jsonPayload:
+		ses.Request.Body = jsonPayloadEncoded
+	case rawPayload:
+		ses.Request.Body = rawPayloadEncoded
+	case file:
+		ses.Request.Body = filePayload
+	}
+	if withResponse {
+		ses.Timing = m.timing
+		ses.Filter = m.filter
+		if m.filter != "" {
+			ses.Response.RawBody = m.resBody
+		}
+	}
+	return ses
+}
+
 // Load session: create and populate request and response from the given file.
 func loadSession(m model, r io.ReadCloser) (tea.Model, tea.Cmd) {
 	ses, _ := NewSession(
@@ -772,47 +1447,283 @@ func loadSession(m model, r io.ReadCloser) (tea.Model, tea.Cmd) {
 	// Update state (request and response and some other stuff)
 	// TODO update suggestions and all this to separate function
 	sbar.SetReqCount(ses.ReqCount)
-	formValues = ses.Request.FormValues
+	m.setRequest(ses.Request)
+	m.setResponse(ses.Response)
+	m.setPayload(ses.Request)
+	m.timing = ses.Timing
+	m.filter = ses.Filter // body lines of response are filtered already
+	m.restoreTree()
+	if ses.Cookies != nil {
+		jar.Replace(activeEnv, ses.Cookies)
+		m.saveJar()
+	}
 
-	// Create a new request instance
-	m.req = newReqest()
+	return m, nil
+}
 
-	// req scheme (http or https)
-	m.req.URL.Scheme = ses.Request.Scheme
-	idx := checkboxIndex(https)
-	if m.req.URL.Scheme == "https" {
-		m.checkboxes[idx].SetOn()
+// Set response from the given [Response] data.
+func (m *model) setResponse(r Response) {
+	m.res = &http.Response{Request: m.req}
+	m.res.Status = r.Status
+	m.res.Proto = r.Proto
+	m.res.Header = r.Headers
+	m.resBodyLines = r.BodyLines
+	m.resBody = r.RawBody
+	if m.resBody == "" {
+		m.resBody = stripEscapes(strings.Join(r.BodyLines, "\n"))
+	}
+	m.tree = nil
+	m.offset = 0
+	m.reqPayload = nothing
+	m.search.Find(m.search.query, m.resBodyLines)
+}
+
+// Set filter of JSON response and render body of response.
+func (m *model) setFilter(f string) bool {
+	m.filter = strings.TrimSpace(f)
+	return m.res == nil || m.renderBody()
+}
+
+// Render body of response: the whole one or the result of filter, errors of filter
+// are shown in the status bar and the whole body is rendered. JSON body is rendered
+// as tree in tree mode.
+func (m *model) renderBody() bool {
+	autoformat := m.checkboxes[checkboxIndex(autoformat)].IsOn()
+	m.offset = 0
+	m.tree = nil
+	ok := true
+	ct, body := m.res.Header.Get("content-type"), m.resBody
+	if m.filter != "" {
+		out, err := filterJSON(m.filter, m.resBody, autoformat)
+		if err == nil {
+			ct, body, autoformat = "application/json", out, false
+		} else {
+			sbar.Error("filter: " + err.Error())
+			ok = false
+		}
+	}
+	if m.treeMode {
+		if tree, err := NewJSONTree(body); err == nil {
+			m.tree = tree
+			m.renderTree()
+			return ok
+		}
+	}
+	m.resBodyLines = formatRespBody(ct, body, autoformat)
+	m.search.Find(m.search.query, m.resBodyLines)
+	return ok
+}
+
+// Render JSON tree of restored response in tree mode, the saved body lines are kept
+// if the body is not JSON (e.g. long lines of it are wrapped).
+func (m *model) restoreTree() {
+	if !m.treeMode {
+		return
+	}
+	if _, err := NewJSONTree(m.resBody); err == nil {
+		m.renderBody()
+	}
+}
+
+// Render lines of JSON tree and scroll to its cursor.
+func (m *model) renderTree() {
+	m.resBodyLines = m.tree.Lines()
+	m.search.Find(m.search.query, m.resBodyLines)
+	m.scrollToTreeCursor()
+}
+
+// Scroll response body to make the node under cursor of JSON tree visible.
+func (m *model) scrollToTreeCursor() {
+	available := max(screenHeight-usedScreenLines, 1)
+	switch cursor := m.tree.Cursor(); {
+	case cursor < m.offset:
+		m.offset = cursor
+	case cursor >= m.offset+available:
+		m.offset = cursor - available + 1
+	}
+}
+
+// JSON tree is shown and focused.
+func (m *model) treeFocused() bool {
+	return m.focused == treeView && m.tree != nil
+}
+
+// Update JSON tree after change of its nodes (lines are rendered again) or its cursor.
+func (m *model) updateTree(changed bool) {
+	if changed {
+		m.renderTree()
+		return
+	}
+	m.scrollToTreeCursor()
+}
+
+// Toggle tree mode of JSON response and focus the tree.
+func (m *model) toggleTree() {
+	if m.treeMode && m.focused != treeView && m.tree != nil {
+		m.focusTree() // the tree is shown, but not focused
+		return
+	}
+	m.treeMode = !m.treeMode
+	if !m.treeMode {
+		if m.focused == treeView {
+			m.focusFirstInput()
+		}
+		if m.res != nil {
+			m.renderBody()
+		}
+		sbar.Info("JSON tree is off")
+		return
+	}
+	if m.res != nil {
+		m.renderBody()
+	}
+	if m.tree == nil {
+		sbar.Info("JSON tree is on, it is shown for JSON responses")
+		return
+	}
+	m.focusTree()
+	sbar.Info("JSON tree is on")
+}
+
+// Focus JSON tree.
+func (m *model) focusTree() {
+	m.blurAllPrompts()
+	m.textArea.Blur()
+	m.history.Blur()
+	m.focused = treeView
+}
+
+// Search query in response body and scroll to the current match.
+func (m *model) searchBody(query string) {
+	if err := m.search.Find(query, m.resBodyLines); err != nil {
+		sbar.Error("search: " + err.Error())
+		return
+	}
+	m.scrollToMatch()
+}
+
+// Scroll response body to make the current match of search visible.
+func (m *model) scrollToMatch() {
+	if m.search.query == "" {
+		return
+	}
+	match, ok := m.search.Current()
+	if !ok {
+		sbar.Warning(m.search.Status())
+		return
+	}
+	available := screenHeight - usedScreenLines
+	if match.line < m.offset || match.line >= m.offset+available {
+		m.offset = max(min(match.line, len(m.resBodyLines)-available), 0)
+	}
+	sbar.Info(m.search.Status())
+}
+
+// Restore request and response of history entry.
+func loadHistoryEntry(m model, e HistoryEntry) (tea.Model, tea.Cmd) {
+	m.setRequest(e.Request)
+	m.setResponse(e.Response)
+	m.timing = e.Timing
+	m.filter = e.Filter
+	if e.Response.BodyLines == nil {
+		m.renderBody() // the body is saved as is
 	} else {
-		m.checkboxes[idx].SetOff()
+		m.restoreTree()
 	}
+	m.setPayload(e.Request)
+	sbar.Info("restored request of " + e.Time.Format(time.DateTime) + " from history")
+	return m, nil
+}
 
-	// req method
-	m.req.Method = ses.Request.Method
-	m.inputs[method].SetValue(ses.Request.Method)
+// Open request of collection.
+func loadCollectionItem(m model, item *CollectionItem) (tea.Model, tea.Cmd) {
+	m.setRequest(item.Session.Request)
+	m.clearRespArtefacts()
+	m.setPayload(item.Session.Request)
+	sbar.Info("opened request of collection: " + item.Name)
+	return m, nil
+}
 
-	// req host
-	m.req.Host = ses.Request.Host
-	m.req.URL.Host = ses.Request.Host
-	m.inputs[host].SetValue(ses.Request.Host)
+// Append executed request and its response to history.
+func (m *model) appendHistory() {
+	ses := m.newSession(true)
+	if err := m.history.Append(NewHistoryEntry(time.Now(), *ses, m.resBody)); err != nil {
+		sbar.Error("cannot save history: " + err.Error())
+	}
+}
 
-	// req url path
-	m.req.URL.Path = ses.Request.UrlPath
-	m.inputs[urlPath].SetValue(ses.Request.UrlPath)
+// Save cookie jar on disk, errors are shown in the status bar.
+func (m *model) saveJar() {
+	if err := jar.Save(); err != nil {
+		sbar.Error("cannot save cookie jar: " + err.Error())
+	}
+}
 
-	// req headers
-	m.req.Header = ses.Request.Headers
+// Import request from curl command line.
+func importCurl(m model, s string) (tea.Model, tea.Cmd) {
+	c, err := ParseCurl(s)
+	if err != nil {
+		sbar.Error("cannot import curl command: " + err.Error())
+		return m, nil
+	}
+	m.setImported(c)
 
-	// req query params
-	m.req.URL.RawQuery = ses.Request.RawQuery
+	if len(c.Warnings) > 0 {
+		sbar.Warning("curl command is imported: " + strings.Join(c.Warnings, ", "))
+	} else {
+		sbar.Info("curl command is imported")
+	}
+	return m, nil
+}
 
-	// Create a new response instance
-	m.res = &http.Response{Request: m.req}
-	m.res.Status = ses.Response.Status
-	m.res.Proto = ses.Response.Proto
-	m.res.Header = ses.Response.Headers
-	m.resBodyLines = ses.Response.BodyLines
-	m.reqPayload = nothing
+// Set request and payload of parsed curl command or command line arguments.
+func (m *model) setImported(c *Curl) {
+	m.setRequest(c.Request)
+	m.clearRespArtefacts()
+	m.textArea.Reset()
+	m.reqPayload = c.Payload
+
+	switch c.Payload {
+	case formPayload, multipartPayload:
+		m.checkboxes[checkboxIndex(multipartForm)].SetOff()
+		if c.Payload == multipartPayload {
+			m.checkboxes[checkboxIndex(multipartForm)].SetOn()
+		}
+		m.setFormPayload()
+	case jsonPayload:
+		m.textArea.SetValue(autoFormatJSON(c.JSON))
+		m.setReqJsonPayload()
+		m.req.Method = c.Method // restore method overridden by JSON payload
+		m.inputs[method].SetValue(c.Method)
+	case rawPayload:
+		m.setPayload(Request{Payload: payloadNames[rawPayload], Body: c.Raw})
+	}
+}
 
+// Open session file or prefill request by command line arguments (if any).
+func loadArgs(m model, sessionPath string, args []string) (model, error) {
+	switch {
+	case sessionPath != "" && len(args) > 0:
+		return m, errors.New("session and request arguments cannot be used together")
+	case sessionPath != "":
+		f, err := os.Open(expandHome(sessionPath))
+		if err != nil {
+			return m, err
+		}
+		sbar.Info("load session from: " + sessionPath)
+		ses, _ := loadSession(m, f)
+		return ses.(model), nil
+	case len(args) > 0:
+		c, err := ParseRequestArgs(args, formArgs)
+		if err != nil {
+			return m, err
+		}
+		if c.Scheme == "" {
+			c.Scheme = m.req.URL.Scheme // default scheme of https checkbox
+		}
+		m.setImported(c)
+		sbar.Info("request is set by command line arguments")
+	}
 	return m, nil
 }
 
@@ -861,9 +1772,24 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			sbar.Error(msg.Error.Error())
 			return m, nil
 		}
-		ses, _ := NewSession(
-			m.req, m.res, sbar.GetReqCount(),
-			sbar.GetResTime(), formValues, m.resBodyLines)
+		if msg.Id == export {
+			m.focused = 0
+			m.focusPrompt(0)
+			_, err := io.WriteString(msg.Writer, exportRequest(m.req, m.reqPayload, m.exportFormat))
+			if err == nil {
+				err = msg.Writer.Close()
+			}
+			if err != nil {
+				sbar.Error(err.Error())
+				return m, nil
+			}
+			sbar.Info("exported request as " + exportFormatNames[m.exportFormat] + " to: " + msg.Path)
+			return m, nil
+		}
+		ses := m.newSession(true)
+		if useCookieJar {
+			ses.Cookies = jar.List(activeEnv)
+		}
 		err := ses.Save(msg.Writer)
 		if err != nil {
 			sbar.Error(err.Error())
@@ -872,12 +1798,100 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.focused = 0
 		m.focusPrompt(0)
 		return m, nil
+	case PromptSubmitted:
+		idx := promptIndex(msg.Id)
+		m.prompts[idx].Hide()
+		m.prompts[idx].Reset()
+		m.focused = 0
+		m.focusPrompt(0)
+		switch msg.Id {
+		case curlImport:
+			return importCurl(m, msg.Value)
+		case customContentType:
+			m.focused = jsonEditView
+			m.blurAllPrompts()
+			m.textArea.Focus()
+			if msg.Value != "" {
+				m.customCT = msg.Value
+				sbar.Info("content type of body: " + msg.Value)
+			}
+		case proxyOverride:
+			reqProxy = strings.TrimSpace(msg.Value)
+			m.setProxyIndicator()
+			switch reqProxy {
+			case "":
+				sbar.Info("proxy of request is reset to settings")
+			case proxyDirect:
+				sbar.Info("proxy is off for request")
+			default:
+				sbar.Info("proxy of request: " + reqProxy)
+			}
+		case bodySearch:
+			m.searchBody(msg.Value)
+		case jsonFilter:
+			switch {
+			case !m.setFilter(msg.Value):
+			case m.filter == "":
+				sbar.Info("filter of response is removed")
+			default:
+				sbar.Info("filter of response: " + m.filter)
+			}
+		case cookieEdit:
+			m.focused = jarView
+			m.blurAllPrompts()
+			if strings.TrimSpace(msg.Value) == "" {
+				return m, nil
+			}
+			if err := jar.Edit(activeEnv, expandRequest(m.req).URL, msg.Value); err != nil {
+				sbar.Error(err.Error())
+				return m, nil
+			}
+			m.saveJar()
+			sbar.Info("cookie is saved to jar")
+		case tlsOverride:
+			t, err := ParseTLSOverride(msg.Value)
+			if err != nil {
+				sbar.Error(err.Error())
+				return m, nil
+			}
+			reqTLS = t
+			if _, ok := m.transport(); ok {
+				sbar.Info("TLS options of request: " + tlsSettings.Merge(reqTLS).String())
+			}
+		case collectionRename:
+			m.focused = collectionView
+			m.blurAllPrompts()
+			if msg.Value == "" {
+				return m, nil
+			}
+			if err := m.collection.Rename(msg.Value); err != nil {
+				sbar.Error("cannot rename: " + err.Error())
+				return m, nil
+			}
+			sbar.Info("renamed to: " + msg.Value)
+		}
 	case CheckboxUpdated: // todo: move this to checkboxHandler (Checkbox.Update loop)
 		switch msg.Id {
 		case https:
 			m.setHttps(msg.On)
+		case noRedirects:
+			skipRedirects = msg.On
+		case cookieJar:
+			useCookieJar = msg.On
+		case insecure:
+			if _, ok := m.transport(); ok && msg.On {
+				sbar.Warning("verification of server certificates is turned off")
+			}
+		case multipartForm:
+			m.setFormPayload()
 		}
 	case Timer:
+		if msg.id != m.reqId { // late message of cancelled or superseded request
+			if r, ok := msg.payload.(*http.Response); ok {
+				r.Body.Close()
+			}
+			return m, nil
+		}
 		sbar.SetResTime(msg.elapsedTime())
 		cmd := func() tea.Msg {
 			return msg.payload
@@ -886,18 +1900,51 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case *http.Response:
 		defer msg.Body.Close()
+		if msg.Request != nil && msg.Request.Context() != m.req.Context() {
+			return m, nil // response of cancelled request
+		}
 		buf, _ := io.ReadAll(msg.Body)
+		m.cancelReq()
 		m.res = msg
-		m.resBodyLines = formatRespBody(
-			m.res.Header.Get("content-type"), string(buf),
-			m.checkboxes[checkboxIndex(autoformat)].IsOn())
+		if m.trace != nil {
+			t := m.trace.Timing(time.Now())
+			m.timing = &t
+		}
+		m.resBody = string(buf)
+		m.renderBody()
 		sbar.SetResStatusCode(m.res.StatusCode)
 		sbar.SetResProto(m.res.ProtoMajor, m.res.Proto, m.req.URL.Scheme)
+		m.setProxyIndicator()
+		sbar.SetTokenExpiry(time.Time{})
+		if reqAuth.Type == authOAuth2 {
+			if t := cachedOAuth2Token(reqAuth); t != nil {
+				sbar.SetTokenExpiry(t.Expiry)
+			}
+		}
 		sbar.Info("request is executed, response taken")
-		if len(redirects) > 0 {
+		if s := m.res.TLS; s != nil && len(s.PeerCertificates) > 0 {
+			if w := certWarning(s.PeerCertificates[0], time.Now()); w != "" {
+				sbar.Warning("server certificate " + w)
+			}
+		}
+		m.redirects = append(slices.Clone(m.stepHops), redirects...)
+		m.stepHops = nil
+		if len(m.redirects) > 0 {
+			var hops []string
+			for _, h := range m.redirects {
+				hops = append(hops, h.String())
+			}
 			sbar.Warning(
-				strconv.Itoa(len(redirects)) + " redirects: " + strings.Join(redirects, " → "))
+				strconv.Itoa(len(m.redirects)) + " redirects: " + strings.Join(hops, " → "))
+		}
+		if u := redirectLocation(m.res); u != nil {
+			sbar.Info("redirect to " + u.String() + " is not followed, use " +
+				m.keys.FollowRedirect.Help().Key + " to follow it")
 		}
+		if useCookieJar {
+			m.saveJar()
+		}
+		m.appendHistory()
 
 	case tea.WindowSizeMsg:
 		sbar.Info(
@@ -908,6 +1955,12 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case tea.KeyMsg:
 		m.pressedKey = msg.String()
 		switch {
+		case m.treeFocused() && key.Matches(msg, m.keys.PageDown):
+			m.tree.Move(offsetShift)
+			m.scrollToTreeCursor()
+		case m.treeFocused() && key.Matches(msg, m.keys.PageUp):
+			m.tree.Move(-offsetShift)
+			m.scrollToTreeCursor()
 		case key.Matches(msg, m.keys.PageDown):
 			availableScreenLines := screenHeight - usedScreenLines
 			if m.offset+offsetShift+availableScreenLines <= len(m.resBodyLines) {
@@ -935,17 +1988,39 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			sbar.Info("full screen mode is on")
 			return m, tea.EnterAltScreen
 		case key.Matches(msg, m.keys.Run):
-			sbar.Info("sending request...")
-			m.clearRespArtefacts()
-			sbar.IncReqCount()
-			cmd := func() tea.Msg {
-				r, err := sendRequest(m.req, m.reqPayload)
-				if err != nil {
-					return NewMessageWithTimer(err)
-				}
-				return NewMessageWithTimer(r)
+			m.stepHops = nil
+			return m, m.runRequest()
+		case key.Matches(msg, m.keys.FollowRedirect):
+			return m, m.followRedirect()
+		case key.Matches(msg, m.keys.Redirects):
+			if m.rpView == redirectView {
+				m.rpView = helpView
+			} else {
+				m.rpView = redirectView
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.Cancel):
+			if !m.reqIsInFlight() {
+				sbar.Warning("there is no request in flight")
+				return m, nil
 			}
-			return m, cmd
+			m.cancelReq()
+			m.reqId++ // drop late messages of cancelled request
+			sbar.Cancelled("request is cancelled")
+			return m, nil
+		case key.Matches(msg, m.keys.SwitchEnv):
+			if len(environments) == 0 {
+				sbar.Warning("there are no environments in config")
+				return m, nil
+			}
+			name := nextEnvironment()
+			sbar.SetEnvironment(name)
+			if name == "" {
+				sbar.Info("environment is off")
+			} else {
+				sbar.Info("switched to environment: " + name)
+			}
+			return m, nil
 		case key.Matches(msg, m.keys.Prev):
 			m.prevInput()
 			return m, nil
@@ -997,8 +2072,207 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.focused = 0
 				m.focusPrompt(0)
 			}
+		case key.Matches(msg, m.keys.History):
+			switch m.focused {
+			case historyView:
+				m.rpView = helpView
+				m.history.Blur()
+				m.focused = 0
+				m.focusPrompt(0)
+			default:
+				m.rpView = historyView
+				m.focused = historyView
+				m.blurAllPrompts()
+				m.textArea.Blur()
+				m.history.Focus()
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.Collection):
+			switch m.focused {
+			case collectionView, collectionRename:
+				m.rpView = helpView
+				m.focused = 0
+				m.blurAllPrompts()
+				m.focusPrompt(0)
+			default:
+				m.rpView = collectionView
+				m.focused = collectionView
+				m.blurAllPrompts()
+				m.textArea.Blur()
+				m.history.Blur()
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.Filter):
+			m.togglePrompt(jsonFilter)
+			m.prompts[promptIndex(jsonFilter)].SetValue(m.filter)
+			return m, nil
+		case key.Matches(msg, m.keys.Search):
+			m.togglePrompt(bodySearch)
+			m.prompts[promptIndex(bodySearch)].SetValue(m.search.query)
+			return m, nil
+		case key.Matches(msg, m.keys.SearchRegex), key.Matches(msg, m.keys.SearchCase):
+			if key.Matches(msg, m.keys.SearchRegex) {
+				m.search.ToggleRegex()
+			} else {
+				m.search.ToggleCase()
+			}
+			m.prompts[promptIndex(bodySearch)].SetTitle(m.search.Title())
+			m.searchBody(m.search.query)
+			if m.search.query == "" {
+				sbar.Info(strings.TrimSuffix(m.search.Title(), ": "))
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.NextMatch), key.Matches(msg, m.keys.PrevMatch):
+			if m.search.query == "" {
+				sbar.Warning("there is no search, use " + m.keys.Search.Help().Key + " to search")
+				return m, nil
+			}
+			if key.Matches(msg, m.keys.NextMatch) {
+				m.search.Next()
+			} else {
+				m.search.Prev()
+			}
+			m.scrollToMatch()
+			return m, nil
+		case key.Matches(msg, m.keys.CookieJar):
+			switch m.focused {
+			case jarView, cookieEdit:
+				m.rpView = helpView
+				m.focused = 0
+				m.blurAllPrompts()
+				m.focusPrompt(0)
+			default:
+				m.rpView = jarView
+				m.focused = jarView
+				m.blurAllPrompts()
+				m.textArea.Blur()
+				m.history.Blur()
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.JSONTree):
+			m.toggleTree()
+			return m, nil
+		case key.Matches(msg, m.keys.CopyPath):
+			if m.tree == nil {
+				sbar.Warning("there is no JSON tree, use " + m.keys.JSONTree.Help().Key + " to show it")
+				return m, nil
+			}
+			path := m.tree.Path()
+			if err := clipboard.WriteAll(path); err != nil {
+				sbar.Error("cannot copy to clipboard: " + err.Error())
+				return m, nil
+			}
+			sbar.Info("copied path " + path + " to clipboard")
+			return m, nil
+		case m.treeFocused() && key.Matches(msg, m.keys.Up):
+			m.tree.Move(-1)
+			m.scrollToTreeCursor()
+			return m, nil
+		case m.treeFocused() && key.Matches(msg, m.keys.Down):
+			m.tree.Move(1)
+			m.scrollToTreeCursor()
+			return m, nil
+		case m.treeFocused() && key.Matches(msg, m.keys.Expand):
+			m.updateTree(m.tree.Expand())
+			return m, nil
+		case m.treeFocused() && key.Matches(msg, m.keys.Collapse):
+			m.updateTree(m.tree.Collapse())
+			return m, nil
+		case m.treeFocused() && (key.Matches(msg, m.keys.Enter) || key.Matches(msg, m.keys.ToggleCheckbox)):
+			m.updateTree(m.tree.Toggle())
+			return m, nil
+		case m.focused == jarView && key.Matches(msg, m.keys.Up):
+			jar.Up()
+			return m, nil
+		case m.focused == jarView && key.Matches(msg, m.keys.Down):
+			jar.Down(activeEnv)
+			return m, nil
+		case key.Matches(msg, m.keys.Conn):
+			if m.rpView == connView {
+				m.rpView = helpView
+			} else {
+				m.rpView = connView
+			}
+			return m, nil
+		case key.Matches(msg, m.keys.Signature):
+			if m.rpView == signatureView {
+				m.rpView = helpView
+			} else {
+				m.rpView = signatureView
+			}
+			return m, nil
+		case m.focused == collectionView && key.Matches(msg, m.keys.Up):
+			m.collection.Up()
+			return m, nil
+		case m.focused == collectionView && key.Matches(msg, m.keys.Down):
+			m.collection.Down()
+			return m, nil
+		case m.focused == collectionView && key.Matches(msg, m.keys.Rename):
+			idx := promptIndex(collectionRename)
+			m.togglePrompt(collectionRename)
+			m.prompts[idx].SetValue(m.collection.SelectedName())
+			return m, nil
+		case m.focused == collectionView && key.Matches(msg, m.keys.Duplicate):
+			if err := m.collection.Duplicate(); err != nil {
+				sbar.Error("cannot duplicate: " + err.Error())
+				return m, nil
+			}
+			sbar.Info("duplicated: " + m.collection.SelectedName())
+			return m, nil
+		case key.Matches(msg, m.keys.SaveToCollection):
+			// the selected request is overwritten only from the collection panel
+			save := m.collection.Add
+			if m.focused == collectionView {
+				save = m.collection.Put
+			}
+			name, err := save(*m.newSession(false))
+			if err != nil {
+				sbar.Error("cannot save to collection: " + err.Error())
+				return m, nil
+			}
+			sbar.Info("saved request to collection: " + name)
+			return m, nil
+		case m.focused == historyView && key.Matches(msg, m.keys.Up):
+			m.history.Up()
+			return m, nil
+		case m.focused == historyView && key.Matches(msg, m.keys.Down):
+			m.history.Down()
+			return m, nil
+		case key.Matches(msg, m.keys.Export):
+			// every press switches format: curl → HTTPie → Go → hidden
+			idx := fileinputIndex(export)
+			switch {
+			case !m.fileInputs[idx].IsVisible():
+				m.blurAllPrompts()
+				m.exportFormat = exportCurl
+				m.fileInputs[idx].SetVisible()
+				m.fileInputs[idx].Focus()
+				m.focused = export
+			case m.exportFormat+1 < exportFormatsEnd:
+				m.exportFormat++
+			default:
+				m.blurAllPrompts()
+				m.focused = 0
+				m.focusPrompt(0)
+			}
+			m.fileInputs[idx].SetTitle("Export " + exportFormatNames[m.exportFormat] + ": ")
+			return m, nil
+		case key.Matches(msg, m.keys.ImportCurl):
+			m.togglePrompt(curlImport)
+			return m, nil
+		case key.Matches(msg, m.keys.Proxy):
+			m.togglePrompt(proxyOverride)
+			m.prompts[promptIndex(proxyOverride)].SetValue(reqProxy)
+			return m, nil
+		case key.Matches(msg, m.keys.TLS):
+			m.togglePrompt(tlsOverride)
+			m.prompts[promptIndex(tlsOverride)].SetValue(reqTLS.String())
+			return m, nil
 		case key.Matches(msg, m.keys.Delete):
 			switch m.focused {
+			case curlImport, collectionRename, customContentType, proxyOverride, tlsOverride, cookieEdit, bodySearch,
+				jsonFilter:
+				m.prompts[promptIndex(m.focused)].Reset()
 			case header, headerVal:
 				m.delReqHeader()
 			case param, paramVal:
@@ -1008,10 +2282,32 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case form, formVal:
 				m.delReqForm()
 			case jsonEditView:
-				sbar.Warning("remove JSON payload and Content-Type header")
+				sbar.Warning("remove payload and Content-Type header")
 				m.textArea.Reset()
 				m.reqPayload = nothing
 				m.req.Header.Del("Content-Type")
+			case historyView:
+				m.history.ResetFilter()
+			case jarView:
+				c, ok := jar.Selected(activeEnv)
+				if !ok {
+					return m, nil
+				}
+				jar.Delete(activeEnv)
+				m.saveJar()
+				sbar.Warning("deleted cookie from jar: " + c.Name + " of " + c.Domain)
+			case collectionView:
+				name := m.collection.SelectedName()
+				deleted, err := m.collection.Delete()
+				switch {
+				case err != nil:
+					sbar.Error("cannot delete: " + err.Error())
+				case deleted:
+					sbar.Warning("deleted from collection: " + name)
+				default:
+					sbar.Warning("folder " + name + " contains requests, press " + m.keys.Delete.Help().Key +
+						" again to delete it")
+				}
 			case payload:
 				sbar.Warning("remove req payload")
 				filePayload = ""
@@ -1025,8 +2321,18 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			switch m.focused {
 			case https:
 				return m.checkboxHandler(msg, https)
+			case insecure:
+				return m.checkboxHandler(msg, insecure)
 			case autoformat:
 				return m.checkboxHandler(msg, autoformat)
+			case multipartForm:
+				return m.checkboxHandler(msg, multipartForm)
+			case validateBody:
+				return m.checkboxHandler(msg, validateBody)
+			case noRedirects:
+				return m.checkboxHandler(msg, noRedirects)
+			case cookieJar:
+				return m.checkboxHandler(msg, cookieJar)
 			}
 		case key.Matches(msg, m.keys.ToggleJSON):
 			switch m.focused {
@@ -1039,12 +2345,21 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.rpView = jsonEditView
 				m.focused = jsonEditView
 				m.blurAllPrompts()
-				m.textArea.SetHeight(rH)
+				m.history.Blur()
+				m.textArea.SetHeight(rH - 1) // -1 line of body type
 				m.textArea.SetWidth(rW)
 				m.textArea.Focus()
 			}
 		case key.Matches(msg, m.keys.SaveJSON):
-			m.setReqJsonPayload()
+			m.setReqBody()
+		case m.focused == jsonEditView && key.Matches(msg, m.keys.BodyType):
+			m.nextBodyType()
+			if m.bodyType == bodyCustom {
+				m.textArea.Blur()
+				m.togglePrompt(customContentType)
+				m.prompts[promptIndex(customContentType)].SetValue(m.customCT)
+			}
+			return m, nil
 		case key.Matches(msg, m.keys.Enter):
 			switch m.focused {
 			case header, headerVal:
@@ -1055,6 +2370,8 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.setReqCookie()
 			case form, formVal:
 				m.setReqForm()
+			case auth, authVal, secret, secretVal, scope, scopeVal:
+				m.setReqAuth()
 			case method:
 				m.restoreReqMethod() // disallow changing the value by enter
 			case urlPath:
@@ -1070,10 +2387,55 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case payload:
 				idx := fileinputIndex(payload)
 				return m, m.fileInputs[idx].OpenFile()
+			case export:
+				idx := fileinputIndex(export)
+				if m.fileInputs[idx].Value() != "" {
+					return m, m.fileInputs[idx].OpenFile()
+				}
+				m.fileInputs[idx].Hide()
+				m.focused = 0
+				m.focusPrompt(0)
+				err := clipboard.WriteAll(exportRequest(m.req, m.reqPayload, m.exportFormat))
+				if err != nil {
+					sbar.Error("cannot copy to clipboard: " + err.Error())
+					return m, nil
+				}
+				sbar.Info("copied request as " + exportFormatNames[m.exportFormat] + " to clipboard")
+				return m, nil
+			case curlImport, collectionRename, customContentType, proxyOverride, tlsOverride, cookieEdit, bodySearch,
+				jsonFilter:
+				idx := promptIndex(m.focused)
+				return m, m.prompts[idx].Submit()
+			case collectionView:
+				item, ok := m.collection.Selected()
+				if !ok {
+					return m, nil
+				}
+				m.rpView = helpView
+				m.focused = 0
+				m.focusPrompt(0)
+				return loadCollectionItem(m, item)
 			case jsonEditView:
 				var c tea.Cmd
 				m.textArea, c = m.textArea.Update(msg)
+				m.editorTop = editorScroll(m.textArea, m.editorTop)
 				return m, c
+			case jarView:
+				m.togglePrompt(cookieEdit)
+				if c, ok := jar.Selected(activeEnv); ok {
+					m.prompts[promptIndex(cookieEdit)].SetValue(c.String())
+				}
+				return m, nil
+			case historyView:
+				e, ok := m.history.Selected()
+				if !ok {
+					return m, nil
+				}
+				m.rpView = helpView
+				m.history.Blur()
+				m.focused = 0
+				m.focusPrompt(0)
+				return loadHistoryEntry(m, e)
 			}
 
 			// after handling enter is done, go to next input..
@@ -1081,6 +2443,7 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 
 	case error:
+		m.cancelReq()
 		sbar.Error(msg.Error())
 		m.clearRespArtefacts()
 		return m, tea.ClearScreen
@@ -1098,12 +2461,32 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		cmds = append(cmds, c)
 	}
 
+	// Update prompts
+	for i := 0; i < len(m.prompts); i++ {
+		m.prompts[i], c = m.prompts[i].Update(msg)
+		cmds = append(cmds, c)
+	}
+
+	// Incremental search in response body
+	if m.focused == bodySearch {
+		if q := m.prompts[promptIndex(bodySearch)].Value(); q != m.search.query {
+			m.searchBody(q)
+		}
+	}
+
 	// Update status bar
 	sbar, c = sbar.Update(msg)
 	cmds = append(cmds, c)
 
+	// Update history filter
+	if m.focused == historyView {
+		m.history, c = m.history.Update(msg)
+		cmds = append(cmds, c)
+	}
+
 	// Update text area
 	m.textArea, c = m.textArea.Update(msg)
+	m.editorTop = editorScroll(m.textArea, m.editorTop)
 	cmds = append(cmds, c)
 
 	return m, tea.Batch(cmds...)
@@ -1136,8 +2519,16 @@ func (m model) View() string {
 		lipgloss.JoinHorizontal(
 			lipgloss.Top, " ",
 			m.checkboxes[checkboxIndex(https)].View(),
+			m.checkboxes[checkboxIndex(insecure)].View(),
 			m.checkboxes[checkboxIndex(autoformat)].View(),
 		),
+		lipgloss.JoinHorizontal(
+			lipgloss.Top, " ",
+			m.checkboxes[checkboxIndex(multipartForm)].View(),
+			m.checkboxes[checkboxIndex(validateBody)].View(),
+			m.checkboxes[checkboxIndex(noRedirects)].View(),
+		),
+		" "+m.checkboxes[checkboxIndex(cookieJar)].View(),
 	)
 
 	// Request URL
@@ -1146,6 +2537,9 @@ func (m model) View() string {
 
 	// Request headers
 	reqHeaders = headersPrintf(m.req.Header)
+	if reqAuth.IsSet() {
+		reqHeaders = append(reqHeaders, headersPrintf(http.Header{"Auth": {reqAuth.String()}})...)
+	}
 
 	// Request payload
 	switch m.reqPayload {
@@ -1153,8 +2547,14 @@ func (m model) View() string {
 		reqPayload = " " + bodyStyle.Render(formValues.Encode())
 	case jsonPayload:
 		reqPayload = " " + bodyStyle.Render(jsonPayloadEncoded)
+	case rawPayload:
+		reqPayload = highlight(lexerOf(rawPayloadContentType, rawPayloadEncoded), rawPayloadEncoded)
+		reqPayload = lipgloss.NewStyle().Padding(0, 1).Render(reqPayload)
 	case file:
 		reqPayload = " " + bodyStyle.Render(filePayload, " attached")
+	case multipartPayload:
+		reqPayload = lipgloss.JoinVertical(lipgloss.Left, multipartPreview(formValues)...)
+		reqPayload = bodyStyle.Padding(0, 1).Render(reqPayload)
 	}
 
 	// print response
@@ -1193,7 +2593,25 @@ func (m model) View() string {
 	case helpView:
 		rv = lipgloss.NewStyle().Width(rW).Render(m.help.View(m.keys))
 	case jsonEditView:
-		rv = lipgloss.NewStyle().Width(rW).Height(rH).Render(m.textArea.View())
+		bt, ct := bodyTypes[m.bodyType].Name, bodyTypes[m.bodyType].ContentType
+		if m.bodyType == bodyCustom {
+			bt, ct = bt+" "+m.customCT, m.customCT
+		}
+		rv = lipgloss.NewStyle().Width(rW).Height(rH).Render(lipgloss.JoinVertical(lipgloss.Left,
+			pressedKeyPromptStyle.Render("Body: ")+pressedKeyTextStyle.Render(bt),
+			editorView(m.textArea, ct, m.editorTop)))
+	case historyView:
+		rv = lipgloss.NewStyle().Width(rW).Height(rH).Render(m.history.View(rW, rH))
+	case collectionView:
+		rv = lipgloss.NewStyle().Width(rW).Height(rH).Render(m.collection.View(rW, rH))
+	case redirectView:
+		rv = lipgloss.NewStyle().Width(rW).Height(rH).Render(redirectChainView(m.redirects, m.res))
+	case connView:
+		rv = lipgloss.NewStyle().Width(rW).Height(rH).Render(connDetailsView(m.res, m.trace, m.timing, rW))
+	case jarView:
+		rv = lipgloss.NewStyle().Width(rW).Height(rH).Render(jar.View(activeEnv, rW, rH))
+	case signatureView:
+		rv = lipgloss.NewStyle().Width(rW).Height(rH).Render(signatureDebugView())
 	}
 	rpContent := []string{
 		rv,
@@ -1207,6 +2625,12 @@ func (m model) View() string {
 				lipgloss.NewStyle().Width(rW).Render(m.fileInputs[i].View()))
 		}
 	}
+	for i := 0; i < len(m.prompts); i++ {
+		if m.prompts[i].IsVisible() {
+			rpContent = append(rpContent,
+				lipgloss.NewStyle().Width(rW).Render(m.prompts[i].View()))
+		}
+	}
 	rightPanel := lipgloss.JoinVertical(lipgloss.Center, rpContent...)
 
 	menuRendered := lipgloss.JoinHorizontal(
@@ -1233,7 +2657,13 @@ func (m model) View() string {
 		lipgloss.Height(reqInfoRendered) +
 		lipgloss.Height(preResInfoRendered) +
 		1 // +1 line of status bar
-	resBodyLines = m.getRespPageLines(usedScreenLines)
+	for i, line := range m.getRespPageLines(usedScreenLines) {
+		line = m.search.Highlight(m.offset+i, line)
+		if m.treeFocused() && m.offset+i == m.tree.Cursor() {
+			line = m.tree.CursorLine(line)
+		}
+		resBodyLines = append(resBodyLines, line)
+	}
 	resBodyRendered := lipgloss.JoinVertical(lipgloss.Top, resBodyLines...)
 	resInfoRendered := lipgloss.JoinVertical(lipgloss.Top, preResInfoRendered, resBodyRendered)
 
@@ -1247,6 +2677,7 @@ func main() {
 	// parse args and show help (if needed)
 	flag.Parse()
 	if showHelp {
+		fmt.Println("Usage: rhttp [flags] [METHOD] URL [Header:value] [param==value] [field=value] [field@/path/to/file] [field:=json]\n       " + runUsage)
 		flag.PrintDefaults()
 		os.Exit(0)
 	}
@@ -1261,7 +2692,28 @@ func main() {
 		log.Fatal(err)
 	}
 
-	p := tea.NewProgram(initialModel(conf))
+	if checkConfig {
+		for _, w := range conf.Warnings {
+			fmt.Println(w)
+		}
+		if conf.HasWarnings() {
+			os.Exit(1)
+		}
+		fmt.Println("config is valid")
+		os.Exit(0)
+	}
+
+	if flag.Arg(0) == "run" {
+		color := term.IsTerminal(int(os.Stdout.Fd()))
+		os.Exit(runHeadless(conf, flag.Args()[1:], os.Stdout, os.Stderr, color))
+	}
+
+	m, err := loadArgs(initialModel(conf), sessionPath, flag.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	p := tea.NewProgram(m)
 	if _, err := p.Run(); err != nil {
 		log.Fatal(err)
 	}
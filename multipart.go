@@ -0,0 +1,133 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Part of multipart form: a text field or a file, value of file part looks like
+// @/path/to/file or @/path/to/file;type=image/png, text value starting with @ is escaped: \@text
+type FormPart struct {
+	Name, Value, File, ContentType string
+}
+
+// Parse form value.
+func ParseFormPart(name, value string) FormPart {
+	p := FormPart{Name: name, Value: value}
+	if strings.HasPrefix(value, `\@`) {
+		p.Value = value[1:]
+		return p
+	}
+	if !strings.HasPrefix(value, "@") {
+		return p
+	}
+	p.File, p.ContentType, _ = strings.Cut(strings.TrimPrefix(value, "@"), ";type=")
+	if p.ContentType == "" {
+		p.ContentType = mime.TypeByExtension(filepath.Ext(p.File))
+	}
+	if p.ContentType == "" {
+		p.ContentType = "application/octet-stream"
+	}
+	return p
+}
+
+// Check if form values contain file parts.
+func hasFileParts(v map[string][]string) bool {
+	for _, vals := range v {
+		for _, val := range vals {
+			if strings.HasPrefix(val, "@") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Parts of form in alphabetical order of names.
+func formParts(v map[string][]string) []FormPart {
+	var names []string
+	for name := range v {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var parts []FormPart
+	for _, name := range names {
+		for _, val := range v[name] {
+			parts = append(parts, ParseFormPart(name, val))
+		}
+	}
+	return parts
+}
+
+// Encode form values as multipart body, returns body and its content type with boundary.
+func encodeMultipart(v map[string][]string) (*bytes.Buffer, string, error) {
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for _, p := range formParts(v) {
+		if p.File == "" {
+			if err := w.WriteField(p.Name, p.Value); err != nil {
+				return nil, "", err
+			}
+			continue
+		}
+
+		h := make(textproto.MIMEHeader)
+		h.Set("Content-Disposition", mime.FormatMediaType("form-data",
+			map[string]string{"name": p.Name, "filename": filepath.Base(p.File)}))
+		h.Set("Content-Type", p.ContentType)
+		pw, err := w.CreatePart(h)
+		if err != nil {
+			return nil, "", err
+		}
+		f, err := os.Open(p.File)
+		if err != nil {
+			return nil, "", err
+		}
+		_, err = io.Copy(pw, f)
+		f.Close()
+		if err != nil {
+			return nil, "", err
+		}
+	}
+	if err := w.Close(); err != nil {
+		return nil, "", err
+	}
+	return &body, w.FormDataContentType(), nil
+}
+
+// Human readable size.
+func formatSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return strconv.FormatFloat(float64(n)/(1<<20), 'f', 1, 64) + " MiB"
+	case n >= 1<<10:
+		return strconv.FormatFloat(float64(n)/(1<<10), 'f', 1, 64) + " KiB"
+	}
+	return strconv.FormatInt(n, 10) + " B"
+}
+
+// Preview of multipart form: one line per part with its size.
+func multipartPreview(v map[string][]string) []string {
+	var lines []string
+	for _, p := range formParts(v) {
+		if p.File == "" {
+			lines = append(lines, p.Name+": "+p.Value+" ("+formatSize(int64(len(p.Value)))+")")
+			continue
+		}
+		size := "not found"
+		if st, err := os.Stat(p.File); err == nil {
+			size = formatSize(st.Size())
+		}
+		lines = append(lines, p.Name+": @"+p.File+" ("+p.ContentType+", "+size+")")
+	}
+	return lines
+}
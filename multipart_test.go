@@ -0,0 +1,79 @@
+package main
+
+import (
+	"io"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestParseFormPart(t *testing.T) {
+	for value, expected := range map[string]FormPart{
+		"neo":                        {Name: "f", Value: "neo"},
+		`\@neo`:                      {Name: "f", Value: "@neo"},
+		"@/tmp/a.png":                {Name: "f", Value: "@/tmp/a.png", File: "/tmp/a.png", ContentType: "image/png"},
+		"@/tmp/a.png;type=image/gif": {Name: "f", Value: "@/tmp/a.png;type=image/gif", File: "/tmp/a.png", ContentType: "image/gif"},
+		"@/tmp/a.unknown-ext":        {Name: "f", Value: "@/tmp/a.unknown-ext", File: "/tmp/a.unknown-ext", ContentType: "application/octet-stream"},
+	} {
+		if p := ParseFormPart("f", value); p != expected {
+			t.Errorf("expected %#v of %s, got: %#v", expected, value, p)
+		}
+	}
+}
+
+func TestEncodeMultipart(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(file, []byte("content of file"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	body, ct, err := encodeMultipart(map[string][]string{"name": {"neo", `\@one`}, "doc": {"@" + file}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	mt, params, err := mime.ParseMediaType(ct)
+	if err != nil || mt != "multipart/form-data" {
+		t.Fatalf("expected multipart/form-data content type, got: %s", ct)
+	}
+
+	var parts []string
+	r := multipart.NewReader(body, params["boundary"])
+	for {
+		p, err := r.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, _ := io.ReadAll(p)
+		parts = append(parts, p.FormName()+"|"+p.FileName()+"|"+p.Header.Get("Content-Type")+"|"+string(b))
+	}
+	expected := []string{"doc|a.txt|text/plain; charset=utf-8|content of file", "name|||neo", "name|||@one"}
+	if !slices.Equal(parts, expected) {
+		t.Errorf("expected parts %q, got: %q", expected, parts)
+	}
+
+	if _, _, err := encodeMultipart(map[string][]string{"doc": {"@/not/found"}}); err == nil {
+		t.Error("expected error of missing file")
+	}
+}
+
+func TestMultipartPreview(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "a.rhttp-test")
+	if err := os.WriteFile(file, make([]byte, 2048), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	lines := multipartPreview(map[string][]string{"name": {"neo"}, "doc": {"@" + file}, "gone": {"@/not/found;type=text/plain"}})
+	expected := []string{
+		"doc: @" + file + " (application/octet-stream, 2.0 KiB)",
+		"gone: @/not/found (text/plain, not found)",
+		"name: neo (3 B)",
+	}
+	if !slices.Equal(lines, expected) {
+		t.Errorf("expected %q, got: %q", expected, lines)
+	}
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Token is refreshed this time before its expiry to not send request with token
+// which becomes expired on the way to server.
+const oauthExpiryDelta = 10 * time.Second
+
+// OAuth2Token is an access token taken from token endpoint.
+type OAuth2Token struct {
+	AccessToken  string
+	RefreshToken string
+	Expiry       time.Time // zero means the token never expires
+}
+
+// Check if token can be used.
+func (t *OAuth2Token) Valid() bool {
+	return t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Add(oauthExpiryDelta).Before(t.Expiry))
+}
+
+// Response of token endpoint (RFC 6749 section 5).
+type oauthTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	ExpiresIn        int    `json:"expires_in"`
+	RefreshToken     string `json:"refresh_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// Cache of tokens, the key is made of auth settings, see [oauthCacheKey].
+// The lock is not held while a token is fetched: the channel of in-flight fetch
+// is closed when it's done, so the other requests of the same key wait for it.
+var oauthTokens = struct {
+	sync.Mutex
+	tokens   map[string]*OAuth2Token
+	inflight map[string]chan struct{}
+}{tokens: make(map[string]*OAuth2Token), inflight: make(map[string]chan struct{})}
+
+func oauthCacheKey(a Auth) string {
+	return strings.Join([]string{a.TokenURL, a.ClientID, a.ClientSecret, a.Scopes, a.Audience}, "\n")
+}
+
+// Cached token of auth settings, placeholders of active environment are expanded.
+func cachedOAuth2Token(a Auth) *OAuth2Token {
+	oauthTokens.Lock()
+	defer oauthTokens.Unlock()
+	return oauthTokens.tokens[oauthCacheKey(expandAuth(a))]
+}
+
+// Take access token: the cached one is used until it expires, then it is refreshed
+// by refresh token (if any), otherwise a new one is requested by client credentials.
+// The refresh token of auth settings (Token) is used to get the first access token.
+// Placeholders of auth settings must be expanded by [expandAuth].
+func oauth2Token(ctx context.Context, cli *http.Client, a Auth) (*OAuth2Token, error) {
+	key := oauthCacheKey(a)
+
+	oauthTokens.Lock()
+	for {
+		if t := oauthTokens.tokens[key]; t != nil && t.Valid() {
+			oauthTokens.Unlock()
+			return t, nil
+		}
+		done, ok := oauthTokens.inflight[key]
+		if !ok {
+			break
+		}
+		oauthTokens.Unlock()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		oauthTokens.Lock()
+	}
+	t := oauthTokens.tokens[key]
+	done := make(chan struct{})
+	oauthTokens.inflight[key] = done
+	oauthTokens.Unlock()
+
+	t, err := renewOAuth2Token(ctx, cli, a, t)
+
+	oauthTokens.Lock()
+	defer oauthTokens.Unlock()
+	delete(oauthTokens.inflight, key)
+	close(done)
+	if err != nil {
+		delete(oauthTokens.tokens, key)
+		return nil, err
+	}
+	oauthTokens.tokens[key] = t
+	return t, nil
+}
+
+// Refresh the expired token t (if any) or request a new one, see [oauth2Token].
+func renewOAuth2Token(ctx context.Context, cli *http.Client, a Auth, t *OAuth2Token) (*OAuth2Token, error) {
+	refreshToken := a.Token
+	if t != nil && t.RefreshToken != "" {
+		refreshToken = t.RefreshToken
+	}
+
+	var err error
+	if refreshToken != "" {
+		t, err = fetchOAuth2Token(ctx, cli, a, url.Values{
+			"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+	}
+	if refreshToken == "" || (err != nil && a.ClientSecret != "") {
+		t, err = fetchOAuth2Token(ctx, cli, a, url.Values{"grant_type": {"client_credentials"}})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if t.RefreshToken == "" {
+		t.RefreshToken = refreshToken // server may not rotate refresh tokens
+	}
+	return t, nil
+}
+
+// Request token from token endpoint, client is authenticated by Basic auth.
+func fetchOAuth2Token(ctx context.Context, cli *http.Client, a Auth, v url.Values) (*OAuth2Token, error) {
+	if a.TokenURL == "" {
+		return nil, errors.New("token URL of OAuth2 is not set")
+	}
+	if a.Scopes != "" {
+		v.Set("scope", a.Scopes)
+	}
+	if a.Audience != "" {
+		v.Set("audience", a.Audience)
+	}
+	r, err := http.NewRequestWithContext(ctx, "POST", a.TokenURL, strings.NewReader(v.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	r.Header.Set("Accept", "application/json")
+	r.SetBasicAuth(url.QueryEscape(a.ClientID), url.QueryEscape(a.ClientSecret))
+
+	res, err := cli.Do(r)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var tr oauthTokenResponse
+	if err := json.Unmarshal(body, &tr); err != nil {
+		return nil, errors.New("invalid response of token endpoint: " + res.Status)
+	}
+	if tr.Error != "" {
+		return nil, errors.New("OAuth2 " + v.Get("grant_type") + ": " + tr.Error + " " + tr.ErrorDescription)
+	}
+	if res.StatusCode != http.StatusOK || tr.AccessToken == "" {
+		return nil, errors.New("OAuth2 " + v.Get("grant_type") + ": no access token, " + res.Status)
+	}
+
+	t := OAuth2Token{AccessToken: tr.AccessToken, RefreshToken: tr.RefreshToken}
+	if tr.ExpiresIn > 0 {
+		t.Expiry = time.Now().Add(time.Duration(tr.ExpiresIn) * time.Second)
+	}
+	return &t, nil
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestOAuth2Token(t *testing.T) {
+	var grants []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, secret, _ := r.BasicAuth()
+		if id != "client" || secret != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_client"})
+			return
+		}
+		grant := r.PostFormValue("grant_type")
+		grants = append(grants, grant)
+		res := map[string]any{"access_token": "token" + r.PostFormValue("scope"), "expires_in": 3600}
+		if r.PostFormValue("scope") == "short" {
+			res["expires_in"] = 5 // less than expiry delta
+			res["refresh_token"] = "refresh"
+		}
+		if grant == "refresh_token" && r.PostFormValue("refresh_token") != "refresh" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+		json.NewEncoder(w).Encode(res)
+	}))
+	defer srv.Close()
+
+	a := Auth{Type: authOAuth2, TokenURL: srv.URL, ClientID: "client", ClientSecret: "secret", Scopes: "read"}
+
+	t.Run("client credentials and cache", func(t *testing.T) {
+		grants = nil
+		for i := 0; i < 3; i++ {
+			tok, err := oauth2Token(context.Background(), srv.Client(), a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tok.AccessToken != "tokenread" {
+				t.Errorf("expected access token tokenread, got: %s", tok.AccessToken)
+			}
+		}
+		if len(grants) != 1 || grants[0] != "client_credentials" {
+			t.Errorf("expected one client_credentials grant, got: %v", grants)
+		}
+		if tok := cachedOAuth2Token(a); tok == nil || !tok.Valid() {
+			t.Error("expected cached valid token")
+		}
+	})
+
+	t.Run("refresh token", func(t *testing.T) {
+		grants = nil
+		b := a
+		b.Scopes = "short"
+		for i := 0; i < 2; i++ {
+			if _, err := oauth2Token(context.Background(), srv.Client(), b); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if len(grants) != 2 || grants[0] != "client_credentials" || grants[1] != "refresh_token" {
+			t.Errorf("expected client_credentials and refresh_token grants, got: %v", grants)
+		}
+	})
+
+	t.Run("cache is not locked by fetch", func(t *testing.T) {
+		started, release := make(chan struct{}, 2), make(chan struct{})
+		slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			started <- struct{}{}
+			<-release
+			json.NewEncoder(w).Encode(map[string]any{"access_token": "slow", "expires_in": 3600})
+		}))
+		defer slow.Close()
+		b := a
+		b.TokenURL = slow.URL
+
+		var wg sync.WaitGroup
+		tokens := make([]*OAuth2Token, 2)
+		for i := range tokens {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				tokens[i], _ = oauth2Token(context.Background(), slow.Client(), b)
+			}()
+		}
+		<-started
+		cached := make(chan *OAuth2Token)
+		go func() { cached <- cachedOAuth2Token(b) }()
+		select {
+		case tok := <-cached:
+			if tok != nil {
+				t.Errorf("expected no cached token while it's fetched, got: %v", tok)
+			}
+		case <-time.After(time.Second):
+			t.Error("expected cached token is taken while a token is fetched")
+		}
+		close(release)
+		wg.Wait()
+		if len(started) != 0 || tokens[0] == nil || tokens[0] != tokens[1] {
+			t.Errorf("expected the same fetched token, got: %v %v", tokens[0], tokens[1])
+		}
+	})
+
+	t.Run("invalid client", func(t *testing.T) {
+		b := a
+		b.ClientSecret = "wrong"
+		if _, err := oauth2Token(context.Background(), srv.Client(), b); err == nil {
+			t.Error("expected error of invalid client")
+		}
+	})
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// A Prompt is a one line text input of the right panel, when the value is submitted
+// the [PromptSubmitted] will be emitted, use Id to distinguish prompts.
+type Prompt struct {
+	id      int
+	widget  textinput.Model
+	visible bool
+}
+
+type PromptSubmitted struct {
+	Id    int
+	Value string
+}
+
+func (p *Prompt) Submit() tea.Cmd {
+	v := p.widget.Value()
+	return func() tea.Msg {
+		return PromptSubmitted{p.id, v}
+	}
+}
+
+func (p *Prompt) SetTitle(s string) {
+	p.widget.Prompt = s
+}
+
+func (p *Prompt) SetVisible() {
+	p.visible = true
+}
+
+func (p *Prompt) Hide() {
+	p.widget.Blur()
+	p.visible = false
+}
+
+func (p *Prompt) IsVisible() bool {
+	return p.visible
+}
+
+func (p *Prompt) Focus() {
+	p.widget.Focus()
+}
+
+func (p *Prompt) Value() string {
+	return p.widget.Value()
+}
+
+func (p *Prompt) SetValue(s string) {
+	p.widget.SetValue(s)
+}
+
+func (p *Prompt) Reset() {
+	p.widget.Reset()
+}
+
+func (p *Prompt) Blur() {
+	p.widget.Blur()
+}
+
+func (p Prompt) View() string {
+	return p.widget.View()
+}
+
+func NewPrompt(id int, title, placeholder string, colors ...lipgloss.Color) Prompt {
+	w := textinput.New()
+	w.Prompt = title
+	w.Placeholder = placeholder
+	w.Width = 25
+	w.PromptStyle = lipgloss.NewStyle().Foreground(colors[0]).Bold(true)
+	w.PlaceholderStyle = lipgloss.NewStyle().Foreground(colors[1])
+	w.TextStyle = lipgloss.NewStyle().Foreground(colors[2])
+	return Prompt{id: id, widget: w}
+}
+
+func (p Prompt) Update(msg tea.Msg) (Prompt, tea.Cmd) {
+	var cmd tea.Cmd
+	p.widget, cmd = p.widget.Update(msg)
+	return p, cmd
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Proxy settings.
+type Proxy struct {
+	HTTP    string   `json:"HTTP"`    // proxy of http requests
+	HTTPS   string   `json:"HTTPS"`   // proxy of https requests
+	SOCKS5  string   `json:"SOCKS5"`  // proxy of all requests if HTTP or HTTPS proxy is not set, socks5:// is default scheme
+	NoProxy []string `json:"NoProxy"` // hosts, domains (.example.com) and CIDRs bypassing proxy
+}
+
+// The value of proxy override of request which turns proxy off.
+const proxyDirect = "direct"
+
+var (
+	proxySettings Proxy
+	reqProxy      string // proxy override of current request: proxy URL or "direct"
+)
+
+// Check if host is in no proxy list.
+func (p *Proxy) bypass(host string) bool {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(host)
+	ip := net.ParseIP(host)
+	for _, np := range p.NoProxy {
+		np = strings.ToLower(strings.TrimSpace(np))
+		switch {
+		case np == "":
+		case np == "*" || np == host:
+			return true
+		case strings.HasPrefix(np, "."):
+			if strings.HasSuffix(host, np) || host == np[1:] {
+				return true
+			}
+		case ip != nil:
+			if _, n, err := net.ParseCIDR(np); err == nil && n.Contains(ip) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Proxy URL of settings for the given request URL, empty means no proxy.
+func (p *Proxy) of(u *url.URL) string {
+	if p.bypass(u.Host) {
+		return ""
+	}
+	proxy := p.HTTP
+	if u.Scheme == "https" {
+		proxy = p.HTTPS
+	}
+	if proxy == "" && p.SOCKS5 != "" {
+		proxy = p.SOCKS5
+		if !strings.Contains(proxy, "://") {
+			proxy = "socks5://" + proxy
+		}
+	}
+	return proxy
+}
+
+// Check if no proxy is set, the no proxy list alone does not matter.
+func (p *Proxy) IsEmpty() bool {
+	return p.HTTP == "" && p.HTTPS == "" && p.SOCKS5 == ""
+}
+
+// Proxy URL of request: the override of request (proxy URL or "direct") has priority
+// over settings (no proxy list is not applied to it), nil means no proxy.
+func proxyOf(p Proxy, override string, u *url.URL) (*url.URL, error) {
+	proxy := override
+	switch proxy {
+	case proxyDirect:
+		return nil, nil
+	case "":
+		proxy = p.of(u)
+	}
+	if proxy == "" {
+		return nil, nil
+	}
+	if !strings.Contains(proxy, "://") {
+		proxy = "http://" + proxy
+	}
+	return url.Parse(proxy)
+}
+
+// Proxy function of transport: the settings and the override of request (with
+// expanded variables) are captured, the proxy of environment (HTTP_PROXY, HTTPS_PROXY
+// and NO_PROXY) is used if both are empty.
+func proxyFunc(p Proxy, override string) func(*http.Request) (*url.URL, error) {
+	if override == "" && p.IsEmpty() {
+		return http.ProxyFromEnvironment
+	}
+	return func(r *http.Request) (*url.URL, error) {
+		return proxyOf(p, override, r.URL)
+	}
+}
+
+// Settings of transport: TLS settings with insecure flag and proxy override of request
+// with expanded variables.
+type transportKey struct {
+	tls      TLS
+	insecure bool
+	proxy    string
+}
+
+// Transports of requests by their settings, they are shared between requests to reuse
+// connections, the cache is used by the goroutine of UI only.
+var transports = make(map[transportKey]*http.Transport)
+
+// Transport of requests with the given settings, it's created once and reused.
+func transportOf(t TLS, insecure bool, proxy string) (*http.Transport, error) {
+	k := transportKey{t, insecure, proxy}
+	if tr, ok := transports[k]; ok {
+		return tr, nil
+	}
+	c, err := t.Config(insecure)
+	if err != nil {
+		return nil, err
+	}
+	tr := newTransport(c, proxyFunc(proxySettings, proxy))
+	transports[k] = tr
+	return tr, nil
+}
+
+// Create transport of requests with the given TLS config (nil is the default one)
+// and proxy function (nil means no proxy).
+func newTransport(c *tls.Config, proxy func(*http.Request) (*url.URL, error)) *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = proxy
+	t.TLSClientConfig = c
+	return t
+}
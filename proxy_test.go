@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"testing"
+)
+
+func TestProxy(t *testing.T) {
+	p := Proxy{
+		HTTP: "http://proxy:8080", SOCKS5: "socks5://proxy:1080",
+		NoProxy: []string{"localhost", ".internal.net", "10.0.0.0/8"},
+	}
+
+	t.Run("settings", func(t *testing.T) {
+		for rawURL, expected := range map[string]string{
+			"http://example.com/":      "http://proxy:8080",
+			"https://example.com/":     "socks5://proxy:1080",
+			"http://localhost:8080/":   "",
+			"http://api.internal.net/": "",
+			"http://internal.net/":     "",
+			"http://10.1.2.3/":         "",
+			"http://11.1.2.3/":         "http://proxy:8080",
+		} {
+			u, _ := url.Parse(rawURL)
+			if v := p.of(u); v != expected {
+				t.Errorf("expected proxy %q of %s, got: %q", expected, rawURL, v)
+			}
+		}
+	})
+
+	t.Run("default scheme", func(t *testing.T) {
+		u, _ := url.Parse("https://example.com/")
+		for _, c := range []struct {
+			settings Proxy
+			expected string
+		}{
+			{Proxy{HTTPS: "proxy:8080"}, "http://proxy:8080"},
+			{Proxy{SOCKS5: "proxy:1080"}, "socks5://proxy:1080"},
+			{Proxy{HTTP: "proxy:8080", SOCKS5: "proxy:1080"}, "socks5://proxy:1080"},
+		} {
+			if v, err := proxyOf(c.settings, "", u); err != nil || v.String() != c.expected {
+				t.Errorf("expected proxy %s of %+v, got: %v", c.expected, c.settings, v)
+			}
+		}
+	})
+
+	t.Run("override", func(t *testing.T) {
+		u, _ := url.Parse("http://localhost/")
+		if v, err := proxyOf(p, "127.0.0.1:8888", u); err != nil || v.String() != "http://127.0.0.1:8888" {
+			t.Errorf("expected override proxy http://127.0.0.1:8888, got: %v", v)
+		}
+		u, _ = url.Parse("http://example.com/")
+		if v, _ := proxyOf(p, proxyDirect, u); v != nil {
+			t.Errorf("expected no proxy, got: %v", v)
+		}
+	})
+
+	t.Run("environment", func(t *testing.T) {
+		env := reflect.ValueOf(http.ProxyFromEnvironment).Pointer()
+		if f := proxyFunc(Proxy{NoProxy: []string{"localhost"}}, ""); reflect.ValueOf(f).Pointer() != env {
+			t.Error("expected proxy of environment without settings and override")
+		}
+		r := httptest.NewRequest("GET", "http://example.com/", nil)
+		if v, err := proxyFunc(p, "")(r); err != nil || v.String() != "http://proxy:8080" {
+			t.Errorf("expected proxy of settings, got: %v", v)
+		}
+		if v, err := proxyFunc(Proxy{}, "127.0.0.1:8888")(r); err != nil || v.String() != "http://127.0.0.1:8888" {
+			t.Errorf("expected override proxy, got: %v", v)
+		}
+	})
+}
+
+func TestTransportOf(t *testing.T) {
+	defer clear(transports)
+	t1, err := transportOf(TLS{}, false, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if t2, _ := transportOf(TLS{}, false, ""); t2 != t1 {
+		t.Error("expected the same transport of the same settings")
+	}
+	if t2, _ := transportOf(TLS{}, true, ""); t2 == t1 || !t2.TLSClientConfig.InsecureSkipVerify {
+		t.Error("expected a new insecure transport")
+	}
+	if t2, _ := transportOf(TLS{}, false, "127.0.0.1:8888"); t2 == t1 {
+		t.Error("expected a new transport of proxy override")
+	}
+	if _, err := transportOf(TLS{MinVersion: "2.0"}, false, ""); err == nil {
+		t.Error("expected error of invalid TLS settings")
+	}
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// RedirectHop is a redirect response of request of redirect chain.
+type RedirectHop struct {
+	Method   string
+	URL      string // URL of redirected request
+	Status   string
+	Code     int
+	Header   http.Header // headers of redirect response
+	Location string      // absolute URL of the next request
+}
+
+// Short description of hop: status code and the next URL.
+func (h RedirectHop) String() string {
+	return strconv.Itoa(h.Code) + " " + h.Location
+}
+
+var (
+	redirects     []RedirectHop // redirects of the last sent request
+	skipRedirects bool          // do not follow redirects, the redirect response is returned
+)
+
+func handleRedirect(req *http.Request, via []*http.Request) error {
+	if skipRedirects {
+		return http.ErrUseLastResponse
+	}
+	redirects = append(redirects, newRedirectHop(via[len(via)-1], req.Response, req.URL))
+	if len(redirects) > maxRedirects {
+		return errors.New("max redirects (" + strconv.Itoa(maxRedirects) + ") followed")
+	}
+	return nil
+}
+
+func newRedirectHop(req *http.Request, res *http.Response, next *url.URL) RedirectHop {
+	return RedirectHop{
+		Method: req.Method, URL: req.URL.String(), Status: res.Status, Code: res.StatusCode,
+		Header: res.Header, Location: next.String(),
+	}
+}
+
+// The next URL of redirect response, nil if it's not a redirect.
+func redirectLocation(res *http.Response) *url.URL {
+	if res == nil || res.Request == nil || res.StatusCode < 300 || res.StatusCode > 399 {
+		return nil
+	}
+	loc := res.Header.Get("Location")
+	if loc == "" {
+		return nil
+	}
+	u, err := res.Request.URL.Parse(loc)
+	if err != nil {
+		return nil
+	}
+	return u
+}
+
+// Method of the next request of redirect: 307 and 308 keep method and body,
+// other redirects of non GET and HEAD requests are followed by GET (as browsers do).
+func redirectMethod(code int, method string) string {
+	switch {
+	case code == http.StatusTemporaryRedirect || code == http.StatusPermanentRedirect:
+		return method
+	case method == http.MethodHead:
+		return method
+	}
+	return http.MethodGet
+}
+
+// Headers with credentials which are not sent to another host on redirect.
+var credentialHeaders = []string{"Authorization", "Www-Authenticate", "Cookie", "Cookie2"}
+
+// Drop credentials of request redirected from the initial URL to another host: auth
+// settings and headers with credentials. They are kept for the same host and its
+// subdomains as net/http does. It returns true if credentials are dropped.
+func dropRedirectCredentials(r *Request, initial, next *url.URL) bool {
+	ihost, nhost := strings.ToLower(initial.Hostname()), strings.ToLower(next.Hostname())
+	if nhost == ihost || strings.HasSuffix(nhost, "."+ihost) {
+		return false
+	}
+	r.Auth = nil
+	for _, h := range credentialHeaders {
+		delete(r.Headers, h)
+	}
+	return true
+}
+
+// Render redirect chain: request, status, headers and cookies of every hop.
+func redirectChainView(hops []RedirectHop, res *http.Response) string {
+	if len(hops) == 0 {
+		return headerValueStyle.Render("there are no redirects")
+	}
+
+	var lines []string
+	for i, h := range hops {
+		lines = append(lines, headerNameStyle.Render("#"+strconv.Itoa(i+1)+" "+h.Method+" "+h.URL),
+			headerValueStyle.Render("→ "+h.Status+" "+h.Location))
+
+		var names []string
+		for name := range h.Header {
+			names = append(names, name)
+		}
+		slices.Sort(names)
+		for _, name := range names {
+			style := headerValueStyle
+			if name == "Set-Cookie" {
+				style = connWarningStyle
+			}
+			for _, v := range h.Header[name] {
+				lines = append(lines, "  "+headerNameStyle.Render(name+": ")+style.Render(v))
+			}
+		}
+		lines = append(lines, "")
+	}
+	if res != nil {
+		lines = append(lines, headerNameStyle.Render("Final: ")+headerValueStyle.Render(res.Status))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestRedirects(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.SetCookie(w, &http.Cookie{Name: "sid", Value: "1"})
+			http.Redirect(w, r, "/b?x=1", http.StatusFound)
+		case "/b":
+			w.Write([]byte("ok"))
+		}
+	}))
+	defer srv.Close()
+	cli := http.Client{CheckRedirect: handleRedirect}
+	maxRedirects = 10
+
+	t.Run("follow", func(t *testing.T) {
+		redirects = nil
+		res, err := cli.Post(srv.URL+"/a", "text/plain", strings.NewReader("body"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusOK || len(redirects) != 1 {
+			t.Fatalf("expected 200 after one redirect, got: %d, %v", res.StatusCode, redirects)
+		}
+		h := redirects[0]
+		if h.Method != "POST" || h.Code != http.StatusFound || h.Location != srv.URL+"/b?x=1" {
+			t.Errorf("unexpected hop: %#v", h)
+		}
+		if h.Header.Get("Set-Cookie") != "sid=1" {
+			t.Errorf("expected Set-Cookie of hop, got: %v", h.Header)
+		}
+		if v := redirectChainView(redirects, res); !strings.Contains(v, "Set-Cookie: sid=1") {
+			t.Errorf("expected cookie in redirect chain view, got:\n%s", v)
+		}
+	})
+
+	t.Run("do not follow", func(t *testing.T) {
+		redirects, skipRedirects = nil, true
+		defer func() { skipRedirects = false }()
+		res, err := cli.Get(srv.URL + "/a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		res.Body.Close()
+		if res.StatusCode != http.StatusFound || len(redirects) != 0 {
+			t.Fatalf("expected 302 without redirects, got: %d, %v", res.StatusCode, redirects)
+		}
+		if u := redirectLocation(res); u == nil || u.String() != srv.URL+"/b?x=1" {
+			t.Errorf("expected location %s/b?x=1, got: %v", srv.URL, u)
+		}
+	})
+
+	t.Run("method", func(t *testing.T) {
+		for _, c := range []struct {
+			code             int
+			method, expected string
+		}{{301, "POST", "GET"}, {302, "GET", "GET"}, {303, "PUT", "GET"}, {307, "POST", "POST"}, {308, "PUT", "PUT"}, {302, "HEAD", "HEAD"}} {
+			if m := redirectMethod(c.code, c.method); m != c.expected {
+				t.Errorf("expected %s after %d of %s, got: %s", c.expected, c.code, c.method, m)
+			}
+		}
+	})
+
+	t.Run("credentials", func(t *testing.T) {
+		initial, _ := url.Parse("https://example.com/login")
+		for next, dropped := range map[string]bool{
+			"https://example.com:8443/a": false,
+			"https://API.example.com/a":  false,
+			"https://other.com/a":        true,
+			"https://badexample.com/a":   true,
+		} {
+			r := Request{
+				Headers: map[string][]string{"Authorization": {"Bearer x"}, "Cookie": {"sid=1"}, "Accept": {"*/*"}},
+				Auth:    &Auth{Type: authBearer, Token: "x"},
+			}
+			u, _ := url.Parse(next)
+			if ok := dropRedirectCredentials(&r, initial, u); ok != dropped {
+				t.Errorf("expected dropped credentials %v of %s, got: %v", dropped, next, ok)
+			}
+			if dropped && (r.Auth != nil || len(r.Headers) != 1 || r.Headers["Accept"] == nil) {
+				t.Errorf("expected only Accept header without auth of %s, got: %v %v", next, r.Headers, r.Auth)
+			}
+			if !dropped && (r.Auth == nil || len(r.Headers) != 3) {
+				t.Errorf("expected kept credentials of %s, got: %v %v", next, r.Headers, r.Auth)
+			}
+		}
+	})
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+const runUsage = "rhttp [flags] run [-fail-on-status code] [-env name] session.json"
+
+// Run request of session file without UI and print status, headers and body of response,
+// the output is highlighted if color is set. Returns exit code: 1 on errors or when
+// status code of response is not less than the fail-on-status threshold, 2 on wrong usage.
+func runHeadless(conf *Config, args []string, stdout, stderr io.Writer, color bool) int {
+	fs := flag.NewFlagSet("run", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	failOnStatus := fs.Int("fail-on-status", 0, "exit with code 1 if status code of response is greater or equal")
+	env := fs.String("env", conf.Environment, "environment of request")
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, "Usage: "+runUsage)
+		fs.PrintDefaults()
+	}
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return 2
+	}
+
+	applySettings(conf)
+	if !setEnvironments(conf.Environments, *env) {
+		fmt.Fprintln(stderr, `environment "`+*env+`" not found`)
+		return 1
+	}
+	skipRedirects = conf.Checkboxes["noRedirects"]
+	if color {
+		headerNameStyle = lipgloss.NewStyle().Foreground(conf.Color("headerName"))
+		headerValueStyle = lipgloss.NewStyle().Foreground(conf.Color("headerValue"))
+	}
+
+	res, body, err := runSession(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(stderr, err)
+		return 1
+	}
+	printResponse(stdout, res, body, color, conf.Checkboxes["autoformat"])
+	if *failOnStatus > 0 && res.StatusCode >= *failOnStatus {
+		fmt.Fprintln(stderr, "status "+res.Status+" meets fail-on-status "+strconv.Itoa(*failOnStatus))
+		return 1
+	}
+	return 0
+}
+
+// Load session from file and send its request, the body of response is read
+// and filtered by the filter of session (if any).
+func runSession(path string) (*http.Response, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, "", err
+	}
+	var ses Session
+	if err := ses.Load(f); err != nil {
+		return nil, "", errors.New("cannot load session " + path + ": " + err.Error())
+	}
+
+	r := ses.Request
+	reqAuth = Auth{}
+	if r.Auth != nil {
+		reqAuth = *r.Auth
+	}
+	reqProxy = r.Proxy
+	reqTLS = TLS{}
+	if r.TLS != nil {
+		reqTLS = *r.TLS
+	}
+	t, err := transportOf(tlsSettings.Merge(reqTLS), r.Insecure, expandVars(r.Proxy))
+	if err != nil {
+		return nil, "", errors.New("TLS: " + err.Error())
+	}
+
+	// cookies of session are sent, but the jar on disk is left untouched
+	useCookieJar = len(ses.Cookies) > 0
+	jar = NewCookieJar("", "", "")
+	jar.Replace(activeEnv, ses.Cookies)
+
+	req := r.NewHTTPRequest()
+	formValues = r.FormValues
+	p := payloadType(r.Payload)
+	switch {
+	case p == jsonPayload:
+		jsonPayloadEncoded = r.Body
+	case p == rawPayload:
+		rawPayloadEncoded, rawPayloadContentType = r.Body, req.Header.Get("Content-Type")
+	case p == file:
+		f, err := os.Open(r.Body)
+		if err != nil {
+			return nil, "", errors.New("cannot open payload: " + err.Error())
+		}
+		req.Body = f
+	case p == nothing && len(formValues) > 0: // session without payload type
+		p = formPayload
+		if hasFileParts(formValues) || strings.HasPrefix(req.Header.Get("Content-Type"), "multipart/") {
+			p = multipartPayload
+		}
+	}
+
+	res, err := sendRequest(newSendState(req, p), t)
+	if err != nil {
+		return nil, "", err
+	}
+	defer res.Body.Close()
+	b, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, "", err
+	}
+	if ses.Filter != "" {
+		body, err := filterJSON(ses.Filter, string(b), false)
+		if err != nil {
+			return nil, "", errors.New("filter: " + err.Error())
+		}
+		return res, body, nil
+	}
+	return res, string(b), nil
+}
+
+// Print status line, headers sorted by name and body of response.
+func printResponse(w io.Writer, res *http.Response, body string, color, autoformat bool) {
+	fmt.Fprintln(w, headerNameStyle.Render(res.Proto+" "+res.Status))
+	var names []string
+	for name := range res.Header {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	for _, name := range names {
+		for _, v := range res.Header[name] {
+			fmt.Fprintln(w, headerNameStyle.Render(name+":")+" "+headerValueStyle.Render(v))
+		}
+	}
+	fmt.Fprintln(w)
+
+	if color && body != "" {
+		lexer := lexerOf(res.Header.Get("Content-Type"), body)
+		if autoformat && lexer.Config().Name == "JSON" {
+			body = autoFormatJSON(body)
+		}
+		body = highlight(lexer, body)
+	}
+	fmt.Fprint(w, body)
+	if body != "" && !strings.HasSuffix(body, "\n") {
+		fmt.Fprintln(w)
+	}
+}
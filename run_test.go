@@ -0,0 +1,128 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Restore globals changed by headless run after the test.
+func restoreRunGlobals(t *testing.T) {
+	j, useJar, skip, auth, proxy, tls := jar, useCookieJar, skipRedirects, reqAuth, reqProxy, reqTLS
+	form, jsonBody, rawBody, rawCT := formValues, jsonPayloadEncoded, rawPayloadEncoded, rawPayloadContentType
+	envs, env, to, redirs := environments, activeEnv, timeout, maxRedirects
+	t.Cleanup(func() {
+		jar, useCookieJar, skipRedirects, reqAuth, reqProxy, reqTLS = j, useJar, skip, auth, proxy, tls
+		formValues, jsonPayloadEncoded, rawPayloadEncoded, rawPayloadContentType = form, jsonBody, rawBody, rawCT
+		environments, activeEnv, timeout, maxRedirects = envs, env, to, redirs
+	})
+}
+
+func TestRunHeadless(t *testing.T) {
+	restoreRunGlobals(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/echo" {
+			b, _ := io.ReadAll(r.Body)
+			w.Write([]byte(r.Header.Get("Content-Type") + " " + string(b)))
+			return
+		}
+		r.ParseForm()
+		c, _ := r.Cookie("sid")
+		w.Header().Set("X-Cookie", c.String())
+		w.Header().Set("Content-Type", "text/plain")
+		if r.URL.Path == "/missing" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+		w.Write([]byte(r.Method + " " + r.Form.Get("name")))
+	}))
+	defer srv.Close()
+	u, _ := url.Parse(srv.URL)
+
+	session := func(path string) string {
+		ses := Session{
+			Request: Request{
+				Scheme: "http", Host: u.Host, Method: "POST", UrlPath: path,
+				FormValues: map[string][]string{"name": {"rhttp"}},
+			},
+			Cookies: []JarCookie{{Name: "sid", Value: "1", Domain: "127.0.0.1", Path: "/", HostOnly: true}},
+		}
+		b, _ := json.Marshal(ses)
+		p := filepath.Join(t.TempDir(), "ses.json")
+		if err := os.WriteFile(p, b, 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return p
+	}
+	conf := &Config{}
+	conf.Timeout = 2
+
+	t.Run("ok", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := runHeadless(conf, []string{"-fail-on-status", "400", session("/ok")}, &stdout, &stderr, false)
+		if code != 0 {
+			t.Errorf("expected exit code 0, got: %d, %s", code, stderr.String())
+		}
+		out := stdout.String()
+		for _, s := range []string{"HTTP/1.1 200 OK\n", "X-Cookie: sid=1\n", "\n\nPOST rhttp\n"} {
+			if !strings.Contains(out, s) {
+				t.Errorf("expected %q in output, got: %s", s, out)
+			}
+		}
+	})
+
+	t.Run("fail on status", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		code := runHeadless(conf, []string{"-fail-on-status", "400", session("/missing")}, &stdout, &stderr, false)
+		if code != 1 || !strings.HasPrefix(stdout.String(), "HTTP/1.1 404 Not Found") {
+			t.Errorf("expected exit code 1 and printed response, got: %d, %s", code, stdout.String())
+		}
+	})
+
+	t.Run("body", func(t *testing.T) {
+		file := filepath.Join(t.TempDir(), "body.txt")
+		if err := os.WriteFile(file, []byte("content of file"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		for _, tc := range []struct {
+			payload, ct, body, expected string
+		}{
+			{"json", "", `{"name":"rhttp"}`, `application/json {"name":"rhttp"}`},
+			{"raw", "application/xml", "<name>rhttp</name>", "application/xml <name>rhttp</name>"},
+			{"file", "text/plain", file, "text/plain content of file"},
+		} {
+			ses := Session{Request: Request{
+				Scheme: "http", Host: u.Host, Method: "POST", UrlPath: "/echo",
+				Headers: map[string][]string{"Content-Type": {tc.ct}}, Payload: tc.payload, Body: tc.body,
+			}}
+			b, _ := json.Marshal(ses)
+			p := filepath.Join(t.TempDir(), "ses.json")
+			if err := os.WriteFile(p, b, 0o600); err != nil {
+				t.Fatal(err)
+			}
+			var stdout, stderr bytes.Buffer
+			if code := runHeadless(conf, []string{p}, &stdout, &stderr, false); code != 0 {
+				t.Errorf("expected exit code 0 of %s payload, got: %d, %s", tc.payload, code, stderr.String())
+			}
+			if !strings.HasSuffix(stdout.String(), "\n\n"+tc.expected+"\n") {
+				t.Errorf("expected %q of %s payload, got: %s", tc.expected, tc.payload, stdout.String())
+			}
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var stdout, stderr bytes.Buffer
+		if code := runHeadless(conf, nil, &stdout, &stderr, false); code != 2 {
+			t.Errorf("expected exit code 2 of usage, got: %d", code)
+		}
+		if code := runHeadless(conf, []string{"missing.json"}, &stdout, &stderr, false); code != 1 {
+			t.Errorf("expected exit code 1 of missing session, got: %d", code)
+		}
+	})
+}
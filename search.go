@@ -0,0 +1,176 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Escape sequences of highlighting of matches: reverse video does not touch colors
+// of syntax highlighting, the current match is underlined as well.
+const (
+	matchStart        = "\x1b[7m"
+	currentMatchStart = "\x1b[7;4m"
+	matchEnd          = "\x1b[27;24m"
+)
+
+// Match of search: line and byte offsets of match in the plain text of line.
+type searchMatch struct {
+	line, start, end int
+}
+
+// BodySearch is a search of regex or literal in lines of response body,
+// the lines may contain escape sequences of syntax highlighting.
+type BodySearch struct {
+	query     string
+	regex     bool // query is a regular expression, otherwise a literal
+	matchCase bool
+	matches   []searchMatch
+	byLine    map[int][]int // indexes of matches by lines
+	current   int
+}
+
+// Title of search prompt reflects search mode.
+func (s *BodySearch) Title() string {
+	mode := "literal"
+	if s.regex {
+		mode = "regex"
+	}
+	if s.matchCase {
+		mode += ", match case"
+	}
+	return "Search (" + mode + "): "
+}
+
+// Toggle regex mode.
+func (s *BodySearch) ToggleRegex() {
+	s.regex = !s.regex
+}
+
+// Toggle case sensitivity.
+func (s *BodySearch) ToggleCase() {
+	s.matchCase = !s.matchCase
+}
+
+// Remove escape sequences from line.
+func stripEscapes(line string) string {
+	var b strings.Builder
+	for i := 0; i < len(line); i++ {
+		if n := escapeLen(line[i:]); n > 0 {
+			i += n - 1
+			continue
+		}
+		b.WriteByte(line[i])
+	}
+	return b.String()
+}
+
+// Length of CSI escape sequence at the start of string, 0 if there is no one.
+func escapeLen(s string) int {
+	if len(s) < 2 || s[0] != '\x1b' || s[1] != '[' {
+		return 0
+	}
+	for i := 2; i < len(s); i++ {
+		if s[i] >= 0x40 && s[i] <= 0x7e {
+			return i + 1
+		}
+	}
+	return len(s)
+}
+
+// Find matches of query in lines, the current match is the first one.
+func (s *BodySearch) Find(query string, lines []string) error {
+	s.query, s.matches, s.byLine, s.current = query, nil, make(map[int][]int), 0
+	if query == "" {
+		return nil
+	}
+	if !s.regex {
+		query = regexp.QuoteMeta(query)
+	}
+	if !s.matchCase {
+		query = "(?i)" + query
+	}
+	re, err := regexp.Compile(query)
+	if err != nil {
+		return err
+	}
+	for i, line := range lines {
+		for _, loc := range re.FindAllStringIndex(stripEscapes(line), -1) {
+			if loc[0] == loc[1] {
+				continue // empty match
+			}
+			s.byLine[i] = append(s.byLine[i], len(s.matches))
+			s.matches = append(s.matches, searchMatch{i, loc[0], loc[1]})
+		}
+	}
+	return nil
+}
+
+// Move to the next match, after the last one goes the first one.
+func (s *BodySearch) Next() {
+	if len(s.matches) > 0 {
+		s.current = (s.current + 1) % len(s.matches)
+	}
+}
+
+// Move to the previous match, before the first one goes the last one.
+func (s *BodySearch) Prev() {
+	if len(s.matches) > 0 {
+		s.current = (s.current - 1 + len(s.matches)) % len(s.matches)
+	}
+}
+
+// Current match.
+func (s *BodySearch) Current() (searchMatch, bool) {
+	if s.current >= len(s.matches) {
+		return searchMatch{}, false
+	}
+	return s.matches[s.current], true
+}
+
+// Status of search: match n of N.
+func (s *BodySearch) Status() string {
+	if len(s.matches) == 0 {
+		return "no matches of " + s.query
+	}
+	return "match " + strconv.Itoa(s.current+1) + " of " + strconv.Itoa(len(s.matches))
+}
+
+// Highlight matches of the line with the given index, the highlighting is restored
+// after every escape sequence inside the match (it may reset attributes).
+func (s *BodySearch) Highlight(i int, line string) string {
+	idxs := s.byLine[i]
+	if len(idxs) == 0 {
+		return line
+	}
+
+	var b strings.Builder
+	pos, k := 0, 0 // position in plain text and index of the next match
+	start := ""    // escape sequence of the match in progress
+	for j := 0; j < len(line); j++ {
+		if n := escapeLen(line[j:]); n > 0 {
+			b.WriteString(line[j : j+n])
+			b.WriteString(start)
+			j += n - 1
+			continue
+		}
+		if start != "" && pos == s.matches[idxs[k]].end {
+			b.WriteString(matchEnd)
+			start = ""
+			k++
+		}
+		if start == "" && k < len(idxs) && pos == s.matches[idxs[k]].start {
+			start = matchStart
+			if idxs[k] == s.current {
+				start = currentMatchStart
+			}
+			b.WriteString(start)
+		}
+		b.WriteByte(line[j])
+		pos++
+	}
+	if start != "" {
+		b.WriteString(matchEnd)
+	}
+	return b.String()
+}
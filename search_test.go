@@ -0,0 +1,63 @@
+package main
+
+import "testing"
+
+func TestBodySearch(t *testing.T) {
+	lines := []string{
+		" \x1b[38;2;1;2;3m{\x1b[0m",
+		" \x1b[38;2;1;2;3m\"Name\"\x1b[0m: \x1b[38;2;4;5;6m\"rhttp name\"\x1b[0m",
+		" \x1b[38;2;1;2;3m}\x1b[0m",
+	}
+
+	t.Run("literal", func(t *testing.T) {
+		var s BodySearch
+		if err := s.Find("name", lines); err != nil {
+			t.Fatal(err)
+		}
+		if s.Status() != "match 1 of 2" {
+			t.Errorf("expected match 1 of 2, got: %s", s.Status())
+		}
+		expected := " \x1b[38;2;1;2;3m\"" + currentMatchStart + "Name" + matchEnd + "\"\x1b[0m: \x1b[38;2;4;5;6m\"rhttp " +
+			matchStart + "name" + matchEnd + "\"\x1b[0m"
+		if v := s.Highlight(1, lines[1]); v != expected {
+			t.Errorf("expected highlighted line:\n%q\ngot:\n%q", expected, v)
+		}
+		if v := s.Highlight(0, lines[0]); v != lines[0] {
+			t.Errorf("expected line without matches is untouched, got: %q", v)
+		}
+		s.Next()
+		s.Next()
+		if m, _ := s.Current(); m != (searchMatch{1, 2, 6}) {
+			t.Errorf("expected the first match after the last one, got: %v", m)
+		}
+		s.Prev()
+		if m, _ := s.Current(); m != (searchMatch{1, 16, 20}) {
+			t.Errorf("expected the last match before the first one, got: %v", m)
+		}
+	})
+
+	t.Run("match case", func(t *testing.T) {
+		s := BodySearch{matchCase: true}
+		s.Find("name", lines)
+		if s.Status() != "match 1 of 1" {
+			t.Errorf("expected match 1 of 1, got: %s", s.Status())
+		}
+	})
+
+	t.Run("regex", func(t *testing.T) {
+		s := BodySearch{regex: true}
+		if err := s.Find(`^\s*[{}]$`, lines); err != nil {
+			t.Fatal(err)
+		}
+		if s.Status() != "match 1 of 2" {
+			t.Errorf("expected match 1 of 2, got: %s", s.Status())
+		}
+		if err := s.Find("(", lines); err == nil {
+			t.Error("expected error of invalid regex")
+		}
+		s.Find("nothing", lines)
+		if s.Status() != "no matches of nothing" {
+			t.Errorf("expected no matches, got: %s", s.Status())
+		}
+	})
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"io"
 	"net/http"
+	"slices"
 )
 
 // Request reflects the [http.Request] params.
@@ -15,6 +16,47 @@ type Request struct {
 	Headers    map[string][]string `json:"headers"`
 	FormValues map[string][]string `json:"form"`
 	RawQuery   string              `json:"qs"`
+	Auth       *Auth               `json:"auth,omitempty"`
+	Proxy      string              `json:"proxy,omitempty"` // proxy override: proxy URL or "direct"
+	TLS        *TLS                `json:"tls,omitempty"`   // TLS override
+	Insecure   bool                `json:"insecure,omitempty"`
+	Payload    string              `json:"payload,omitempty"` // type of payload, see payloadNames
+	Body       string              `json:"body,omitempty"`    // JSON or raw body, path of file payload
+}
+
+// Names of request payload types in saved requests.
+var payloadNames = []string{
+	nothing: "", jsonPayload: "json", formPayload: "form", file: "file", multipartPayload: "multipart",
+	rawPayload: "raw",
+}
+
+// Payload type of name, unknown names mean no payload.
+func payloadType(name string) int {
+	return max(slices.Index(payloadNames, name), nothing)
+}
+
+// Copy of multi-value map (headers, form values), the values are not shared.
+func cloneValues(v map[string][]string) map[string][]string {
+	c := make(map[string][]string, len(v))
+	for k, vals := range v {
+		c[k] = slices.Clone(vals)
+	}
+	return c
+}
+
+// Deep copy of request: headers, form values, auth and TLS settings are not shared.
+func (r Request) Clone() Request {
+	r.Headers = cloneValues(r.Headers)
+	r.FormValues = cloneValues(r.FormValues)
+	if r.Auth != nil {
+		a := *r.Auth
+		r.Auth = &a
+	}
+	if r.TLS != nil {
+		t := *r.TLS
+		r.TLS = &t
+	}
+	return r
 }
 
 // Response reflects the [http.Response] data.
@@ -23,14 +65,34 @@ type Response struct {
 	Proto     string              `json:"proto"`
 	Headers   map[string][]string `json:"headers"`
 	BodyLines []string            `json:"body"`
+	RawBody   string              `json:"rawBody,omitempty"` // body as is, it's saved along with filter and in history
+}
+
+// Create [http.Request] of the request data, the body is not set.
+func (r *Request) NewHTTPRequest() *http.Request {
+	req := newReqest()
+	req.Method = r.Method
+	req.URL.Scheme = r.Scheme
+	req.Host = r.Host
+	req.URL.Host = r.Host
+	req.URL.Path = r.UrlPath
+	req.URL.RawQuery = r.RawQuery
+	req.Header = cloneValues(r.Headers)
+	if req.Header == nil {
+		req.Header = make(http.Header)
+	}
+	return req
 }
 
 // Session reflect current state: some stats, request settings and last response (with data).
 type Session struct {
-	ReqCount int      `json:"reqCount"`
-	ResTime  string   `json:"resTime"`
-	Request  Request  `json:"req"`
-	Response Response `json:"res"`
+	ReqCount int         `json:"reqCount"`
+	ResTime  string      `json:"resTime"`
+	Timing   *Timing     `json:"timing,omitempty"`
+	Request  Request     `json:"req"`
+	Response Response    `json:"res"`
+	Cookies  []JarCookie `json:"cookies,omitempty"` // cookies of jar of environment
+	Filter   string      `json:"filter,omitempty"`  // filter of JSON response
 }
 
 // Create a new session.
@@ -44,11 +106,9 @@ func NewSession(rq *http.Request, rs *http.Response, rqc int, rqt string, rqf ma
 		req.Scheme = rq.URL.Scheme
 		req.Host = rq.URL.Host
 		req.UrlPath = rq.URL.Path
-		for k, v := range rq.Header {
-			req.Headers[k] = v
-		}
+		req.Headers = cloneValues(rq.Header)
 		req.RawQuery = rq.URL.RawQuery
-		req.FormValues = rqf
+		req.FormValues = cloneValues(rqf)
 	}
 
 	// populate Response with [http.Response] data
@@ -138,4 +138,42 @@ func TestSession(t *testing.T) {
 			t.Errorf("expected res body lines %s != %s", rsb, s.Response.BodyLines)
 		}
 	})
+
+	t.Run("save and load raw body", func(t *testing.T) {
+		s, _ := NewSession(rq, rs, 1, "", rqf, rsb)
+		s.Request.Payload, s.Request.Body = payloadNames[rawPayload], "<user>neo</user>"
+		var buf testWriteCloser
+		if err := s.Save(&buf); err != nil {
+			t.Fatal(err)
+		}
+
+		var loaded Session
+		if err := loaded.Load(io.NopCloser(bytes.NewReader(buf.data))); err != nil {
+			t.Fatal(err)
+		}
+		if payloadType(loaded.Request.Payload) != rawPayload || loaded.Request.Body != "<user>neo</user>" {
+			t.Errorf("expected raw body, got: %s %s", loaded.Request.Payload, loaded.Request.Body)
+		}
+	})
+}
+
+func TestRequestClone(t *testing.T) {
+	r := Request{
+		Headers:    map[string][]string{"Accept": {"*/*"}},
+		FormValues: map[string][]string{"name": {"neo"}},
+		Auth:       &Auth{Type: authBearer, Token: "x"},
+		TLS:        &TLS{ServerName: "a"},
+	}
+	c := r.Clone()
+	c.Headers["Accept"][0] = "text/plain"
+	c.Headers["X-New"] = []string{"1"}
+	c.FormValues["name"] = append(c.FormValues["name"][:0], "trinity")
+	c.Auth.Token, c.TLS.ServerName = "y", "b"
+	if r.Headers["Accept"][0] != "*/*" || len(r.Headers) != 1 || r.FormValues["name"][0] != "neo" ||
+		r.Auth.Token != "x" || r.TLS.ServerName != "a" {
+		t.Errorf("expected original request is untouched, got: %v %v %v %v", r.Headers, r.FormValues, r.Auth, r.TLS)
+	}
+	if c := (Request{}).Clone(); c.Headers == nil || c.FormValues == nil || c.Auth != nil {
+		t.Errorf("expected empty clone of empty request, got: %#v", c)
+	}
 }
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	sigV4Algorithm  = "AWS4-HMAC-SHA256"
+	sigV4TimeFormat = "20060102T150405Z"
+)
+
+// Details of the last signed request, they are shown in the signature view
+// to diagnose signature mismatches.
+var signatureDebug struct {
+	sync.Mutex
+	CanonicalRequest, StringToSign string
+}
+
+// Set details of the last signed request.
+func setSignatureDebug(canonicalRequest, stringToSign string) {
+	signatureDebug.Lock()
+	defer signatureDebug.Unlock()
+	signatureDebug.CanonicalRequest = canonicalRequest
+	signatureDebug.StringToSign = stringToSign
+}
+
+// Get details of the last signed request.
+func getSignatureDebug() (string, string) {
+	signatureDebug.Lock()
+	defer signatureDebug.Unlock()
+	return signatureDebug.CanonicalRequest, signatureDebug.StringToSign
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+func hmacSHA256(key []byte, s string) []byte {
+	h := hmac.New(sha256.New, key)
+	h.Write([]byte(s))
+	return h.Sum(nil)
+}
+
+// URI encode string as AWS requires: everything except unreserved characters
+// is percent encoded, slash is kept if it's requested.
+func awsURIEncode(s string, keepSlash bool) string {
+	var b strings.Builder
+	for _, c := range []byte(s) {
+		if c >= 'A' && c <= 'Z' || c >= 'a' && c <= 'z' || c >= '0' && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' && keepSlash {
+			b.WriteByte(c)
+		} else {
+			b.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{c})))
+		}
+	}
+	return b.String()
+}
+
+// Canonical URI: path segments are encoded once for S3 and twice for other services.
+func sigV4CanonicalURI(r *http.Request, service string) string {
+	path := r.URL.Path
+	if path == "" {
+		return "/"
+	}
+	path = awsURIEncode(path, true)
+	if service != "s3" {
+		path = awsURIEncode(path, true)
+	}
+	return path
+}
+
+// Canonical query string: params are sorted by name and value.
+func sigV4CanonicalQuery(r *http.Request) string {
+	var params []string
+	for k, vals := range r.URL.Query() {
+		for _, v := range vals {
+			params = append(params, awsURIEncode(k, false)+"="+awsURIEncode(v, false))
+		}
+	}
+	slices.Sort(params)
+	return strings.Join(params, "&")
+}
+
+// Canonical headers and the list of signed headers: all headers of request and host.
+func sigV4CanonicalHeaders(r *http.Request) (string, string) {
+	host := r.Host
+	if host == "" {
+		host = r.URL.Host
+	}
+	headers := map[string]string{"host": host}
+	for k, vals := range r.Header {
+		var trimmed []string
+		for _, v := range vals {
+			trimmed = append(trimmed, strings.Join(strings.Fields(v), " "))
+		}
+		headers[strings.ToLower(k)] = strings.Join(trimmed, ",")
+	}
+
+	var names []string
+	for k := range headers {
+		names = append(names, k)
+	}
+	slices.Sort(names)
+
+	var b strings.Builder
+	for _, k := range names {
+		b.WriteString(k + ":" + headers[k] + "\n")
+	}
+	return b.String(), strings.Join(names, ";")
+}
+
+// Sign request by AWS Signature Version 4, the body of request is read and restored.
+// Returns the canonical request and the string to sign.
+func signSigV4(r *http.Request, a Auth, now time.Time) (string, string, error) {
+	var body []byte
+	if r.Body != nil {
+		var err error
+		if body, err = io.ReadAll(r.Body); err != nil {
+			return "", "", err
+		}
+		r.Body.Close()
+		r.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	accessKey, secretKey := a.User, a.Password
+	region, service := a.Region, a.Service
+	payloadHash := sha256Hex(body)
+	amzDate := now.UTC().Format(sigV4TimeFormat)
+	date := amzDate[:8]
+
+	r.Header.Del("Authorization")
+	r.Header.Set("X-Amz-Date", amzDate)
+	if token := a.Token; token != "" {
+		r.Header.Set("X-Amz-Security-Token", token)
+	}
+	if service == "s3" {
+		r.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+
+	headers, signedHeaders := sigV4CanonicalHeaders(r)
+	canonicalRequest := strings.Join([]string{
+		r.Method, sigV4CanonicalURI(r, service), sigV4CanonicalQuery(r),
+		headers, signedHeaders, payloadHash,
+	}, "\n")
+
+	scope := date + "/" + region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		sigV4Algorithm, amzDate, scope, sha256Hex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+secretKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	r.Header.Set("Authorization", sigV4Algorithm+" Credential="+accessKey+"/"+scope+
+		", SignedHeaders="+signedHeaders+", Signature="+signature)
+	return canonicalRequest, stringToSign, nil
+}
+
+// Render details of the last signed request.
+func signatureDebugView() string {
+	canonicalRequest, stringToSign := getSignatureDebug()
+	if stringToSign == "" {
+		return headerValueStyle.Render("there is no signed request yet")
+	}
+	var lines []string
+	if canonicalRequest != "" {
+		lines = append(lines,
+			headerNameStyle.Render("Canonical request:"), headerValueStyle.Render(canonicalRequest), "")
+	}
+	lines = append(lines, headerNameStyle.Render("String to sign:"), headerValueStyle.Render(stringToSign))
+	return strings.Join(lines, "\n")
+}
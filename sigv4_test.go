@@ -0,0 +1,52 @@
+package main
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSignSigV4(t *testing.T) {
+	// get-vanilla of AWS Signature V4 test suite
+	a := Auth{Type: authSigV4, User: "AKIDEXAMPLE", Password: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		Region: "us-east-1", Service: "service"}
+	now := time.Date(2015, 8, 30, 12, 36, 0, 0, time.UTC)
+
+	t.Run("get vanilla", func(t *testing.T) {
+		r, _ := http.NewRequest("GET", "https://example.amazonaws.com/", nil)
+		canonicalRequest, stringToSign, err := signSigV4(r, a, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		expected := "GET\n/\n\nhost:example.amazonaws.com\nx-amz-date:20150830T123600Z\n\nhost;x-amz-date\n" +
+			"e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+		if canonicalRequest != expected {
+			t.Errorf("expected canonical request:\n%s\ngot:\n%s", expected, canonicalRequest)
+		}
+		if !strings.HasPrefix(stringToSign, "AWS4-HMAC-SHA256\n20150830T123600Z\n20150830/us-east-1/service/aws4_request\n") {
+			t.Errorf("unexpected string to sign: %s", stringToSign)
+		}
+		expected = "AWS4-HMAC-SHA256 Credential=AKIDEXAMPLE/20150830/us-east-1/service/aws4_request, " +
+			"SignedHeaders=host;x-amz-date, Signature=5fa00fa31553b73ebf1942676e86291e8372ff2a2260956d9b8aae1d763fbf31"
+		if v := r.Header.Get("Authorization"); v != expected {
+			t.Errorf("expected Authorization: %s, got: %s", expected, v)
+		}
+	})
+
+	t.Run("query and s3", func(t *testing.T) {
+		b := a
+		b.Service = "s3"
+		r, _ := http.NewRequest("PUT", "https://example.amazonaws.com/my bucket/key?b=2&a=1", strings.NewReader("body"))
+		canonicalRequest, _, err := signSigV4(r, b, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(canonicalRequest, "PUT\n/my%20bucket/key\na=1&b=2\n") {
+			t.Errorf("unexpected canonical request: %s", canonicalRequest)
+		}
+		if v := r.Header.Get("X-Amz-Content-Sha256"); v != sha256Hex([]byte("body")) {
+			t.Errorf("expected X-Amz-Content-Sha256 of body, got: %s", v)
+		}
+	})
+}
@@ -15,14 +15,16 @@ const (
 	statusInfo int = iota
 	statusWarning
 	statusError
+	statusCancelled
 )
 
 var (
-	statusProtoHttp2, statusProtoHttps, statusProtoInsecure, statusDefaultIndEmoji string
+	statusProtoHttp2, statusProtoHttps, statusProtoInsecure, statusDefaultIndEmoji, statusTokenEmoji,
+	statusProxyEmoji string
 
 	statusBarStyle, statusNugget, statusBadge, statusBadgeError, statusBadgeOk, statusBadgeWarning,
-	reqCountStyle, resTimeStyle, statusText, statusTextInfo, statusTextError,
-	statusTextWarning, indicatorStyle lipgloss.Style
+	statusBadgeCancelled, reqCountStyle, resTimeStyle, envStyle, tokenStyle, proxyStyle, statusText, statusTextInfo, statusTextError,
+	statusTextWarning, statusTextCancelled, indicatorStyle lipgloss.Style
 )
 
 // A status bar state.
@@ -37,6 +39,9 @@ type StatusBar struct {
 	resStatusCode int
 	resProto      string
 	resProtoMajor int
+	env           string
+	tokenExpiry   time.Time // expiry of OAuth2 access token
+	proxy         string
 }
 
 type StatusBarTickMsg time.Time
@@ -105,6 +110,8 @@ func (s *StatusBar) getStatusText(w int) string {
 		style = statusTextWarning
 	case statusError:
 		style = statusTextError
+	case statusCancelled:
+		style = statusTextCancelled
 	}
 	return style.Render(s.getText(w))
 }
@@ -131,6 +138,11 @@ func (s *StatusBar) Error(text string) {
 	s.setStatus(statusError, text)
 }
 
+// Cancelled message.
+func (s *StatusBar) Cancelled(text string) {
+	s.setStatus(statusCancelled, text)
+}
+
 // Increment count of requests.
 func (s *StatusBar) IncReqCount() {
 	s.reqCount++
@@ -168,6 +180,30 @@ func (s *StatusBar) SetReqCount(c int) {
 	s.reqCount = c
 }
 
+// Set name of active environment.
+func (s *StatusBar) SetEnvironment(name string) {
+	s.env = name
+}
+
+// Set expiry of OAuth2 access token, zero time hides it.
+func (s *StatusBar) SetTokenExpiry(t time.Time) {
+	s.tokenExpiry = t
+}
+
+// Time left till expiry of OAuth2 access token.
+func (s *StatusBar) tokenIndicator() string {
+	left := time.Until(s.tokenExpiry).Round(time.Second)
+	if left <= 0 {
+		return statusTokenEmoji + "expired"
+	}
+	return statusTokenEmoji + left.String()
+}
+
+// Set proxy in use, empty string hides it.
+func (s *StatusBar) SetProxy(proxy string) {
+	s.proxy = proxy
+}
+
 // Set screen width.
 func (s *StatusBar) SetScreenWidth(w int) {
 	s.screenWidth = w
@@ -184,6 +220,8 @@ func (s *StatusBar) getStatusBadge(text string) (badge string) {
 		style = statusBadgeWarning
 	case statusError:
 		style = statusBadgeError
+	case statusCancelled:
+		style = statusBadgeCancelled
 	default:
 		style = statusBadge
 	}
@@ -217,9 +255,24 @@ func (s *StatusBar) FormatStatusBar() string {
 	resTime := resTimeStyle.Render(s.GetResTime())
 	proto := indicatorStyle.Render(s.protoIndicator())
 
-	maxTextWidth := screenWidth - w(status) - w(reqCounter) - w(resTime) - w(proto)
+	var env string
+	if s.env != "" {
+		env = envStyle.Render(s.env)
+	}
+
+	var token string
+	if !s.tokenExpiry.IsZero() {
+		token = tokenStyle.Render(s.tokenIndicator())
+	}
+
+	var proxy string
+	if s.proxy != "" {
+		proxy = proxyStyle.Render(statusProxyEmoji + s.proxy)
+	}
+
+	maxTextWidth := screenWidth - w(status) - w(env) - w(token) - w(proxy) - w(reqCounter) - w(resTime) - w(proto)
 	statusVal := statusText.Copy().Width(maxTextWidth).Render(s.getStatusText(maxTextWidth))
-	bar := lipgloss.JoinHorizontal(lipgloss.Top, status, statusVal, reqCounter, resTime, proto)
+	bar := lipgloss.JoinHorizontal(lipgloss.Top, status, statusVal, env, token, proxy, reqCounter, resTime, proto)
 
 	return statusBarStyle.Width(screenWidth).Render(bar)
 }
@@ -229,6 +282,8 @@ func NewStatusBar(conf *Config) StatusBar {
 	statusProtoHttps = conf.Emoji("statusbarProtoHttps")
 	statusDefaultIndEmoji = conf.Emoji("statusbarDefaultIndicator")
 	statusProtoInsecure = conf.Emoji("statusbarProtoInsecure")
+	statusTokenEmoji = conf.Emoji("statusbarToken")
+	statusProxyEmoji = conf.Emoji("statusbarProxy")
 
 	statusBarStyle = lipgloss.NewStyle().
 		Foreground(conf.Color("statusbarFg")).
@@ -245,11 +300,19 @@ func NewStatusBar(conf *Config) StatusBar {
 		Background(conf.Color("statusbarBadgeOk")).Padding(0, 1)
 	statusBadgeWarning = lipgloss.NewStyle().Inherit(statusBadge).
 		Background(conf.Color("statusbarBadgeWarning")).Padding(0, 1)
+	statusBadgeCancelled = lipgloss.NewStyle().Inherit(statusBadge).
+		Background(conf.Color("statusbarBadgeCancelled")).Padding(0, 1)
 
 	reqCountStyle = statusNugget.Copy().
 		Background(conf.Color("statusbarReqCount")).Align(lipgloss.Right)
 	resTimeStyle = statusNugget.Copy().
 		Background(conf.Color("statusbarResTime")).Align(lipgloss.Right)
+	envStyle = statusNugget.Copy().
+		Background(conf.Color("statusbarEnv")).Align(lipgloss.Right)
+	tokenStyle = statusNugget.Copy().
+		Background(conf.Color("statusbarToken")).Align(lipgloss.Right)
+	proxyStyle = statusNugget.Copy().
+		Background(conf.Color("statusbarProxy")).Align(lipgloss.Right)
 
 	statusText = lipgloss.NewStyle().Inherit(statusBarStyle)
 	statusTextInfo = lipgloss.NewStyle().Inherit(statusText)
@@ -258,6 +321,8 @@ func NewStatusBar(conf *Config) StatusBar {
 
 	statusTextWarning = lipgloss.NewStyle().Inherit(statusText).
 		Foreground(conf.Color("statusbarTextWarning"))
+	statusTextCancelled = lipgloss.NewStyle().Inherit(statusText).
+		Foreground(conf.Color("statusbarTextCancelled"))
 	indicatorStyle = statusNugget.Copy().Background(conf.Color("statusbarIndicator"))
 
 	return StatusBar{}
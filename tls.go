@@ -0,0 +1,125 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"os"
+	"slices"
+	"strings"
+)
+
+// TLS settings, paths of files may start with ~/.
+type TLS struct {
+	CABundle   string `json:"CABundle"`   // PEM file of CA certificates
+	ClientCert string `json:"ClientCert"` // PEM file of client certificate
+	ClientKey  string `json:"ClientKey"`  // PEM file of client key
+	MinVersion string `json:"MinVersion"` // 1.0, 1.1, 1.2 or 1.3
+	MaxVersion string `json:"MaxVersion"`
+	ServerName string `json:"ServerName"` // SNI override
+}
+
+var tlsVersions = map[string]uint16{
+	"1.0": tls.VersionTLS10, "1.1": tls.VersionTLS11, "1.2": tls.VersionTLS12, "1.3": tls.VersionTLS13,
+}
+
+// Keys of TLS override of request.
+var tlsOverrideKeys = []string{"ca", "cert", "key", "min", "max", "sni"}
+
+var (
+	tlsSettings TLS
+	reqTLS      TLS // TLS override of current request, not empty fields override settings
+)
+
+func (t *TLS) fields() []*string {
+	return []*string{&t.CABundle, &t.ClientCert, &t.ClientKey, &t.MinVersion, &t.MaxVersion, &t.ServerName}
+}
+
+// Check if no one field is set.
+func (t TLS) IsEmpty() bool {
+	return t == TLS{}
+}
+
+// Merge settings: not empty fields of the given settings override the current ones.
+func (t TLS) Merge(o TLS) TLS {
+	dst := t.fields()
+	for i, v := range o.fields() {
+		if *v != "" {
+			*dst[i] = *v
+		}
+	}
+	return t
+}
+
+// Format settings as override string: key=value pairs separated by space.
+func (t TLS) String() string {
+	var pairs []string
+	for i, v := range t.fields() {
+		if *v != "" {
+			pairs = append(pairs, tlsOverrideKeys[i]+"="+*v)
+		}
+	}
+	return strings.Join(pairs, " ")
+}
+
+// Parse TLS override: key=value pairs separated by space, keys are ca, cert, key,
+// min, max and sni, e.g. "ca=~/ca.pem min=1.2 sni=api.local".
+func ParseTLSOverride(s string) (TLS, error) {
+	var t TLS
+	fields := t.fields()
+	for _, pair := range strings.Fields(s) {
+		k, v, _ := strings.Cut(pair, "=")
+		i := slices.Index(tlsOverrideKeys, k)
+		if i < 0 || v == "" {
+			return t, errors.New("invalid TLS option: " + pair + ", allowed: " + strings.Join(tlsOverrideKeys, ", "))
+		}
+		*fields[i] = v
+	}
+	return t, nil
+}
+
+// Create config of TLS.
+func (t TLS) Config(insecure bool) (*tls.Config, error) {
+	c := &tls.Config{InsecureSkipVerify: insecure, ServerName: t.ServerName}
+
+	if t.CABundle != "" {
+		pem, err := os.ReadFile(expandHome(t.CABundle))
+		if err != nil {
+			return nil, err
+		}
+		c.RootCAs, _ = x509.SystemCertPool()
+		if c.RootCAs == nil {
+			c.RootCAs = x509.NewCertPool()
+		}
+		if !c.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no certificates found in CA bundle: " + t.CABundle)
+		}
+	}
+
+	if t.ClientCert != "" || t.ClientKey != "" {
+		key := t.ClientKey
+		if key == "" {
+			key = t.ClientCert // the key may be in the same file
+		}
+		cert, err := tls.LoadX509KeyPair(expandHome(t.ClientCert), expandHome(key))
+		if err != nil {
+			return nil, err
+		}
+		c.Certificates = []tls.Certificate{cert}
+	}
+
+	for _, v := range []struct {
+		name string
+		dst  *uint16
+	}{{t.MinVersion, &c.MinVersion}, {t.MaxVersion, &c.MaxVersion}} {
+		if v.name == "" {
+			continue
+		}
+		ver, ok := tlsVersions[strings.TrimPrefix(v.name, "TLS")]
+		if !ok {
+			return nil, errors.New("unknown TLS version: " + v.name)
+		}
+		*v.dst = ver
+	}
+	return c, nil
+}
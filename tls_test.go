@@ -0,0 +1,80 @@
+package main
+
+import (
+	"crypto/tls"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTLS(t *testing.T) {
+	t.Run("override", func(t *testing.T) {
+		o, err := ParseTLSOverride("ca=/tmp/ca.pem min=1.2 sni=api.local")
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := TLS{CABundle: "/etc/ca.pem", MaxVersion: "1.3"}.Merge(o)
+		expected := TLS{CABundle: "/tmp/ca.pem", MinVersion: "1.2", MaxVersion: "1.3", ServerName: "api.local"}
+		if s != expected {
+			t.Errorf("expected %#v, got: %#v", expected, s)
+		}
+		if v := s.String(); v != "ca=/tmp/ca.pem min=1.2 max=1.3 sni=api.local" {
+			t.Errorf("expected override string, got: %s", v)
+		}
+		if _, err := ParseTLSOverride("pin=abc"); err == nil {
+			t.Error("expected error of unknown option")
+		}
+	})
+
+	t.Run("versions", func(t *testing.T) {
+		c, err := TLS{MinVersion: "1.2", MaxVersion: "TLS1.3"}.Config(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if c.MinVersion != tls.VersionTLS12 || c.MaxVersion != tls.VersionTLS13 {
+			t.Errorf("expected TLS 1.2 - 1.3, got: %x - %x", c.MinVersion, c.MaxVersion)
+		}
+		if _, err := (TLS{MinVersion: "2.0"}).Config(false); err == nil {
+			t.Error("expected error of unknown version")
+		}
+	})
+
+	t.Run("CA bundle", func(t *testing.T) {
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer srv.Close()
+
+		get := func(c *tls.Config) error {
+			cli := http.Client{Transport: newTransport(c, nil)}
+			res, err := cli.Get(srv.URL)
+			if err == nil {
+				res.Body.Close()
+			}
+			return err
+		}
+
+		c, _ := TLS{}.Config(false)
+		if get(c) == nil {
+			t.Error("expected error of unknown authority")
+		}
+		c, _ = TLS{}.Config(true)
+		if err := get(c); err != nil {
+			t.Errorf("expected success in insecure mode, got: %s", err)
+		}
+
+		ca := filepath.Join(t.TempDir(), "ca.pem")
+		b := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+		if err := os.WriteFile(ca, b, 0600); err != nil {
+			t.Fatal(err)
+		}
+		c, err := TLS{CABundle: ca}.Config(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := get(c); err != nil {
+			t.Errorf("expected success with CA bundle, got: %s", err)
+		}
+	})
+}
@@ -0,0 +1,227 @@
+package main
+
+import (
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptrace"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// Certificates which expire sooner than this are highlighted.
+const certExpiryWarning = 30 * 24 * time.Hour
+
+var connWarningStyle lipgloss.Style
+
+// Trace of request collected by [httptrace], in case of redirects it's the trace
+// of the last one.
+type Trace struct {
+	RemoteAddr string
+	Reused     bool // connection was reused (keep-alive)
+	WasIdle    bool
+
+	// timestamps of request phases
+	dnsStart, dnsDone, connectStart, connectDone, tlsStart, tlsDone,
+	gotConn, wroteRequest, firstByte time.Time
+}
+
+// Timing is a breakdown of response time by phases of request.
+type Timing struct {
+	DNS      time.Duration `json:"dns"`
+	Connect  time.Duration `json:"connect"`
+	TLS      time.Duration `json:"tls"`
+	TTFB     time.Duration `json:"ttfb"` // time to first byte: from request is sent to response
+	Download time.Duration `json:"download"`
+}
+
+// Client trace which populates the trace.
+func (t *Trace) ClientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		GetConn: func(string) {
+			*t = Trace{} // the next hop of redirects
+		},
+		DNSStart: func(httptrace.DNSStartInfo) { t.dnsStart = time.Now() },
+		DNSDone:  func(httptrace.DNSDoneInfo) { t.dnsDone = time.Now() },
+		ConnectStart: func(string, string) {
+			if t.connectStart.IsZero() { // several addresses may be dialed
+				t.connectStart = time.Now()
+			}
+		},
+		ConnectDone:       func(string, string, error) { t.connectDone = time.Now() },
+		TLSHandshakeStart: func() { t.tlsStart = time.Now() },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.tlsDone = time.Now() },
+		GotConn: func(i httptrace.GotConnInfo) {
+			t.gotConn = time.Now()
+			t.RemoteAddr = i.Conn.RemoteAddr().String()
+			t.Reused = i.Reused
+			t.WasIdle = i.WasIdle
+		},
+		WroteRequest:         func(httptrace.WroteRequestInfo) { t.wroteRequest = time.Now() },
+		GotFirstResponseByte: func() { t.firstByte = time.Now() },
+	}
+}
+
+// Duration between two timestamps, zero if one of them is missed.
+func since(start, end time.Time) time.Duration {
+	if start.IsZero() || end.IsZero() {
+		return 0
+	}
+	return end.Sub(start)
+}
+
+// Timing of request, bodyDone is the time when the body of response is read.
+func (t *Trace) Timing(bodyDone time.Time) Timing {
+	sent := t.wroteRequest
+	if sent.IsZero() {
+		sent = t.gotConn
+	}
+	return Timing{
+		DNS:      since(t.dnsStart, t.dnsDone),
+		Connect:  since(t.connectStart, t.connectDone),
+		TLS:      since(t.tlsStart, t.tlsDone),
+		TTFB:     since(sent, t.firstByte),
+		Download: since(t.firstByte, bodyDone),
+	}
+}
+
+// Render timing as waterfall: one bar per phase, the bar starts where the previous ends.
+func timingView(t Timing, width int) []string {
+	phases := []struct {
+		name string
+		d    time.Duration
+	}{{"DNS", t.DNS}, {"Connect", t.Connect}, {"TLS", t.TLS}, {"TTFB", t.TTFB}, {"Download", t.Download}}
+
+	var total time.Duration
+	for _, p := range phases {
+		total += p.d
+	}
+	barWidth := width - 24 // name and duration columns
+	if total == 0 || barWidth < 10 {
+		barWidth = 10
+	}
+
+	var lines []string
+	var offset time.Duration
+	for _, p := range phases {
+		var start, n int
+		if total > 0 {
+			start = int(int64(offset) * int64(barWidth) / int64(total))
+			n = int(int64(p.d) * int64(barWidth) / int64(total))
+		}
+		if n == 0 && p.d > 0 {
+			n = 1
+		}
+		offset += p.d
+		lines = append(lines, headerNameStyle.Render(padRight(p.name, 9))+
+			strings.Repeat(" ", start)+headerValueStyle.Render(strings.Repeat("█", n))+
+			strings.Repeat(" ", max(barWidth-start-n, 0))+" "+
+			headerValueStyle.Render(p.d.Round(time.Microsecond).String()))
+	}
+	return append(lines, headerNameStyle.Render(padRight("Total", 9))+strings.Repeat(" ", barWidth+1)+
+		headerValueStyle.Render(total.Round(time.Microsecond).String()))
+}
+
+func padRight(s string, n int) string {
+	if len(s) >= n {
+		return s
+	}
+	return s + strings.Repeat(" ", n-len(s))
+}
+
+var tlsVersionNames = map[uint16]string{
+	tls.VersionTLS10: "TLS 1.0", tls.VersionTLS11: "TLS 1.1", tls.VersionTLS12: "TLS 1.2", tls.VersionTLS13: "TLS 1.3",
+}
+
+// Fingerprint of certificate: SHA-256 of its DER encoding.
+func certFingerprint(c *x509.Certificate) string {
+	sum := sha256.Sum256(c.Raw)
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// Warning about validity of certificate, empty if it's fine.
+func certWarning(c *x509.Certificate, now time.Time) string {
+	switch {
+	case now.Before(c.NotBefore):
+		return "not valid yet"
+	case now.After(c.NotAfter):
+		return "expired"
+	case c.NotAfter.Sub(now) < certExpiryWarning:
+		return "expires in " + strconv.Itoa(int(c.NotAfter.Sub(now).Hours()/24)) + " days"
+	}
+	return ""
+}
+
+// Subject alternative names of certificate.
+func certSANs(c *x509.Certificate) []string {
+	sans := append([]string{}, c.DNSNames...)
+	for _, ip := range c.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	for _, u := range c.URIs {
+		sans = append(sans, u.String())
+	}
+	return append(sans, c.EmailAddresses...)
+}
+
+// Render timing and connection details of response: remote address, reuse of connection,
+// TLS version, cipher suite, ALPN protocol and chain of server certificates.
+func connDetailsView(res *http.Response, t *Trace, timing *Timing, width int) string {
+	if res == nil {
+		return headerValueStyle.Render("there is no response yet")
+	}
+
+	var lines []string
+	if timing != nil {
+		lines = append(timingView(*timing, width), "")
+	}
+	add := func(name, val string) {
+		lines = append(lines, headerNameStyle.Render(name+": ")+headerValueStyle.Render(val))
+	}
+
+	if t != nil && t.RemoteAddr != "" {
+		add("Remote address", t.RemoteAddr)
+		add("Reused connection", strconv.FormatBool(t.Reused))
+	}
+	add("Protocol", res.Proto)
+	if res.TLS == nil {
+		return strings.Join(append(lines, "", headerValueStyle.Render("connection is not encrypted")), "\n")
+	}
+
+	s := res.TLS
+	version, ok := tlsVersionNames[s.Version]
+	if !ok {
+		version = "0x" + strconv.FormatUint(uint64(s.Version), 16)
+	}
+	add("TLS version", version)
+	add("Cipher suite", tls.CipherSuiteName(s.CipherSuite))
+	alpn := s.NegotiatedProtocol
+	if alpn == "" {
+		alpn = "none"
+	}
+	add("ALPN", alpn)
+	if s.ServerName != "" {
+		add("Server name", s.ServerName)
+	}
+
+	now := time.Now()
+	for i, c := range s.PeerCertificates {
+		lines = append(lines, "", headerNameStyle.Render("Certificate #"+strconv.Itoa(i)))
+		add("  Subject", c.Subject.String())
+		add("  Issuer", c.Issuer.String())
+		if sans := certSANs(c); len(sans) > 0 {
+			add("  SANs", strings.Join(sans, ", "))
+		}
+		add("  Valid", c.NotBefore.Format(time.DateOnly)+" - "+c.NotAfter.Format(time.DateOnly))
+		add("  SHA-256", certFingerprint(c))
+		if w := certWarning(c, now); w != "" {
+			lines = append(lines, connWarningStyle.Render("  ⚠ certificate "+w))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTrace(t *testing.T) {
+	t.Run("connection details", func(t *testing.T) {
+		srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		defer srv.Close()
+
+		var res *http.Response
+		var tr *Trace
+		var timings []Timing
+		for i := 0; i < 2; i++ {
+			tr = &Trace{}
+			r, _ := http.NewRequestWithContext(
+				httptrace.WithClientTrace(context.Background(), tr.ClientTrace()), "GET", srv.URL, nil)
+			var err error
+			if res, err = srv.Client().Do(r); err != nil {
+				t.Fatal(err)
+			}
+			io.Copy(io.Discard, res.Body)
+			res.Body.Close()
+			timings = append(timings, tr.Timing(time.Now()))
+		}
+		if timings[0].Connect == 0 || timings[0].TLS == 0 || timings[0].TTFB == 0 {
+			t.Errorf("expected connect, TLS and TTFB phases of new connection, got: %#v", timings[0])
+		}
+		if timings[1].Connect != 0 || timings[1].TLS != 0 {
+			t.Errorf("expected no connect and TLS phases of reused connection, got: %#v", timings[1])
+		}
+		if !tr.Reused || tr.RemoteAddr != srv.Listener.Addr().String() {
+			t.Errorf("expected reused connection to %s, got: %#v", srv.Listener.Addr(), tr)
+		}
+
+		v := connDetailsView(res, tr, &timings[0], 80)
+		for _, s := range []string{"Connect", "Total", "TLS 1.3", "Certificate #0", "Reused connection: true", "SHA-256"} {
+			if !strings.Contains(v, s) {
+				t.Errorf("expected %q in connection details, got:\n%s", s, v)
+			}
+		}
+	})
+
+	t.Run("waterfall", func(t *testing.T) {
+		lines := timingView(Timing{DNS: time.Millisecond, Connect: time.Millisecond, TTFB: 2 * time.Millisecond}, 44)
+		if len(lines) != 6 {
+			t.Fatalf("expected 6 lines of waterfall, got: %d", len(lines))
+		}
+		if !strings.HasPrefix(lines[3], "TTFB"+strings.Repeat(" ", 15)+strings.Repeat("█", 10)) || !strings.HasSuffix(lines[5], " 4ms") {
+			t.Errorf("unexpected waterfall:\n%s", strings.Join(lines, "\n"))
+		}
+	})
+
+	t.Run("certificate warnings", func(t *testing.T) {
+		now := time.Now()
+		for expected, c := range map[string]*x509.Certificate{
+			"":                  {NotBefore: now.Add(-time.Hour), NotAfter: now.AddDate(1, 0, 0)},
+			"expired":           {NotBefore: now.AddDate(-1, 0, 0), NotAfter: now.Add(-time.Hour)},
+			"not valid yet":     {NotBefore: now.Add(time.Hour), NotAfter: now.AddDate(1, 0, 0)},
+			"expires in 9 days": {NotBefore: now.AddDate(-1, 0, 0), NotAfter: now.Add(10*24*time.Hour - time.Hour)},
+		} {
+			if w := certWarning(c, now); w != expected {
+				t.Errorf("expected warning %q, got: %q", expected, w)
+			}
+		}
+	})
+}